@@ -6,10 +6,22 @@ type FilesystemDriver struct {
 	Config any    `yaml:"config"`
 }
 
+// 多文件系统配置 按名称配置多个文件系统
+type FilesystemConfig struct {
+	Default string                      `yaml:"default"` // 默认文件系统名称
+	Disks   map[string]FilesystemDriver `yaml:"disks"`   // 文件系统名称 => 驱动配置
+}
+
 // 本地文件系统
 type LocalDriverConfig struct {
 	Root    string `yaml:"root,omitempty"`     // 文件存储根目录 设置后，文件会被限制到此目录下
 	BaseUrl string `yaml:"base_url,omitempty"` // 基础URL, 用于生成完整URL
+
+	RestrictSymlinks bool   `yaml:"restrict_symlinks,omitempty"` // 禁止通过符号链接访问根目录之外的文件
+	FileMode         string `yaml:"file_mode,omitempty"`         // 新建文件的权限 八进制字符串，如 "0640"，默认 0644
+	DirMode          string `yaml:"dir_mode,omitempty"`          // 新建目录的权限 八进制字符串，如 "0750"，默认 0755
+	SyncDir          bool   `yaml:"sync_dir,omitempty"`          // 写入后同步父目录 断电后也不会丢失刚写入的文件
+	SignKey          string `yaml:"sign_key,omitempty"`          // 签名密钥 设置后生成带过期时间的签名URL，需要同时设置 base_url
 }
 
 // 七牛云文件系统
@@ -27,4 +39,96 @@ type WebdavDriverConfig struct {
 	Uri      string `yaml:"uri"`
 	Username string `yaml:"username"`
 	Password string `yaml:"password"`
+	ProxyUrl string `yaml:"proxy_url,omitempty"` // 签名URL代理地址 即 Handler 的访问地址
+	SignKey  string `yaml:"sign_key,omitempty"`  // 签名密钥 与 proxy_url 同时设置后才能生成签名URL
+}
+
+// S3及兼容服务文件系统 如 MinIO、Ceph RGW
+type S3DriverConfig struct {
+	Endpoint     string `yaml:"endpoint,omitempty"`       // 服务地址 为空时使用AWS
+	Region       string `yaml:"region,omitempty"`         // 区域 为空时使用 us-east-1
+	Bucket       string `yaml:"bucket"`                   // 存储桶名称
+	AccessKey    string `yaml:"access_key"`               // 访问密钥ID
+	SecretKey    string `yaml:"secret_key"`               // 访问密钥
+	UsePathStyle bool   `yaml:"use_path_style,omitempty"` // 是否使用路径形式访问存储桶 兼容服务通常需要开启
+	Domain       string `yaml:"domain,omitempty"`         // 访问域名 用于生成完整URL
+	PartSize     int64  `yaml:"part_size,omitempty"`      // 分片上传的分片大小 单位/字节
+}
+
+// 阿里云OSS文件系统
+type OssDriverConfig struct {
+	Endpoint        string `yaml:"endpoint"`            // 地域节点 如 https://oss-cn-hangzhou.aliyuncs.com
+	Bucket          string `yaml:"bucket"`              // 存储桶名称
+	AccessKeyId     string `yaml:"access_key_id"`       // 访问密钥ID
+	AccessKeySecret string `yaml:"access_key_secret"`   // 访问密钥
+	Domain          string `yaml:"domain,omitempty"`    // 访问域名 用于生成完整URL
+	PartSize        int64  `yaml:"part_size,omitempty"` // 分片上传的分片大小 单位/字节
+}
+
+// 腾讯云COS文件系统
+type CosDriverConfig struct {
+	Bucket    string `yaml:"bucket"`               // 存储桶名称 格式为 BucketName-APPID
+	Region    string `yaml:"region,omitempty"`     // 地域 如 ap-guangzhou
+	SecretId  string `yaml:"secret_id"`            // 访问密钥ID
+	SecretKey string `yaml:"secret_key"`           // 访问密钥
+	BucketUrl string `yaml:"bucket_url,omitempty"` // 存储桶地址 为空时根据 bucket 和 region 生成
+	Domain    string `yaml:"domain,omitempty"`     // 访问域名 用于生成完整URL
+	PartSize  int64  `yaml:"part_size,omitempty"`  // 分片上传的分片大小 单位/字节
+}
+
+// SFTP文件系统
+type SftpDriverConfig struct {
+	Host                  string `yaml:"host"`                               // 主机
+	Port                  int    `yaml:"port,omitempty"`                     // 端口 为空时使用22
+	Username              string `yaml:"username"`                           // 用户名
+	Password              string `yaml:"password,omitempty"`                 // 密码
+	PrivateKey            string `yaml:"private_key,omitempty"`              // PEM格式私钥 可以使用 private_key_file 从文件读取
+	Passphrase            string `yaml:"passphrase,omitempty"`               // 私钥密码
+	HostKey               string `yaml:"host_key,omitempty"`                 // 服务器公钥 authorized_keys 格式或 SHA256 指纹
+	InsecureIgnoreHostKey bool   `yaml:"insecure_ignore_host_key,omitempty"` // 不校验服务器公钥 仅用于测试
+	Root                  string `yaml:"root,omitempty"`                     // 根目录 为空时使用登录后的默认目录
+}
+
+// FTP文件系统 仅支持被动模式
+type FtpDriverConfig struct {
+	Host               string `yaml:"host"`                           // 主机
+	Port               int    `yaml:"port,omitempty"`                 // 端口 为空时使用21，隐式FTPS使用990
+	Username           string `yaml:"username,omitempty"`             // 用户名 为空时匿名登录
+	Password           string `yaml:"password,omitempty"`             // 密码
+	Root               string `yaml:"root,omitempty"`                 // 根目录 为空时使用登录后的默认目录
+	TLS                string `yaml:"tls,omitempty"`                  // TLS模式 为空时不加密，explicit 显式FTPS，implicit 隐式FTPS
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"` // 不校验服务器证书 仅用于测试
+	DisableEPSV        bool   `yaml:"disable_epsv,omitempty"`         // 禁用EPSV只使用PASV
+	PoolSize           int    `yaml:"pool_size,omitempty"`            // 保留的空闲连接数
+}
+
+// Azure Blob文件系统 account_key 和 sas_token 二选一
+type AzblobDriverConfig struct {
+	AccountName string `yaml:"account_name"`          // 存储账号名称
+	AccountKey  string `yaml:"account_key,omitempty"` // 账号密钥 生成签名URL需要
+	SasToken    string `yaml:"sas_token,omitempty"`   // SAS令牌 未设置账号密钥时使用
+	Container   string `yaml:"container"`             // 容器名称
+	Endpoint    string `yaml:"endpoint,omitempty"`    // 服务地址 为空时使用 https://<account_name>.blob.core.windows.net
+	Domain      string `yaml:"domain,omitempty"`      // 访问域名 用于生成完整URL
+	BlockSize   int64  `yaml:"block_size,omitempty"`  // 分块上传的分块大小 单位/字节
+}
+
+// Google Cloud Storage文件系统
+type GcsDriverConfig struct {
+	Bucket      string `yaml:"bucket"`                // 存储桶名称
+	Credentials string `yaml:"credentials,omitempty"` // 服务账号JSON密钥 可以使用 credentials_file 从文件读取，为空时使用应用默认凭据
+	Endpoint    string `yaml:"endpoint,omitempty"`    // 服务地址 为空时使用 https://storage.googleapis.com
+	Domain      string `yaml:"domain,omitempty"`      // 访问域名 用于生成完整URL
+	ChunkSize   int    `yaml:"chunk_size,omitempty"`  // 断点续传上传的分块大小 单位/字节
+}
+
+// 压缩包文件系统 只读，支持 zip、tar、tar.gz
+type ArchiveDriverConfig struct {
+	Path    string `yaml:"path"`               // 压缩包路径 根据扩展名判断格式
+	BaseUrl string `yaml:"base_url,omitempty"` // 基础URL, 用于生成完整URL
+}
+
+// 内存文件系统 数据仅保存在进程内，主要用于测试
+type MemoryDriverConfig struct {
+	BaseUrl string `yaml:"base_url,omitempty"` // 基础URL, 用于生成完整URL
 }
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+)
+
+// ErrRequired 必填字段为空
+var ErrRequired = errors.New("required field is empty")
+
+// Validator 可校验的驱动配置，解码配置后自动调用
+type Validator interface {
+	Validate() error
+}
+
+// Validate 校验本地文件系统配置
+func (c LocalDriverConfig) Validate() error {
+	_, fileModeErr := ParseFileMode(c.FileMode)
+	_, dirModeErr := ParseFileMode(c.DirMode)
+	var baseUrlErr error
+	if c.SignKey != "" {
+		baseUrlErr = required("base_url", c.BaseUrl)
+	}
+	return errors.Join(
+		baseUrlErr,
+		validUrl("base_url", c.BaseUrl),
+		wrapField("file_mode", fileModeErr),
+		wrapField("dir_mode", dirModeErr),
+	)
+}
+
+// Validate 校验七牛云文件系统配置
+func (c QiniuDriverConfig) Validate() error {
+	return errors.Join(
+		required("access_key", c.AccessKey),
+		required("access_secret", c.AccessSecret),
+		required("bucket", c.Bucket),
+		required("domain", c.Domain),
+	)
+}
+
+// Validate 校验Webdav文件系统配置
+func (c WebdavDriverConfig) Validate() error {
+	if err := required("uri", c.Uri); err != nil {
+		return err
+	}
+	u, err := url.Parse(c.Uri)
+	if err != nil {
+		return fmt.Errorf("uri: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("uri: unsupported scheme %q, expected http or https", u.Scheme)
+	}
+
+	var proxyUrlErr error
+	if c.SignKey != "" {
+		proxyUrlErr = required("proxy_url", c.ProxyUrl)
+	}
+	return errors.Join(proxyUrlErr, validUrl("proxy_url", c.ProxyUrl))
+}
+
+// Validate 校验S3文件系统配置
+func (c S3DriverConfig) Validate() error {
+	return errors.Join(
+		required("bucket", c.Bucket),
+		required("access_key", c.AccessKey),
+		required("secret_key", c.SecretKey),
+		validUrl("endpoint", c.Endpoint),
+		validUrl("domain", c.Domain),
+	)
+}
+
+// Validate 校验阿里云OSS文件系统配置
+func (c OssDriverConfig) Validate() error {
+	return errors.Join(
+		required("endpoint", c.Endpoint),
+		required("bucket", c.Bucket),
+		required("access_key_id", c.AccessKeyId),
+		required("access_key_secret", c.AccessKeySecret),
+		validUrl("domain", c.Domain),
+	)
+}
+
+// Validate 校验腾讯云COS文件系统配置 未设置 bucket_url 时 region 必填
+func (c CosDriverConfig) Validate() error {
+	var regionErr error
+	if c.BucketUrl == "" {
+		regionErr = required("region", c.Region)
+	}
+	return errors.Join(
+		required("bucket", c.Bucket),
+		regionErr,
+		required("secret_id", c.SecretId),
+		required("secret_key", c.SecretKey),
+		validUrl("bucket_url", c.BucketUrl),
+		validUrl("domain", c.Domain),
+	)
+}
+
+// Validate 校验SFTP文件系统配置 密码和私钥至少设置一个，未设置 insecure_ignore_host_key 时 host_key 必填
+func (c SftpDriverConfig) Validate() error {
+	var authErr, hostKeyErr error
+	if c.Password == "" && c.PrivateKey == "" {
+		authErr = fmt.Errorf("password or private_key: %w", ErrRequired)
+	}
+	if !c.InsecureIgnoreHostKey {
+		hostKeyErr = required("host_key", c.HostKey)
+	}
+	return errors.Join(
+		required("host", c.Host),
+		required("username", c.Username),
+		authErr,
+		hostKeyErr,
+	)
+}
+
+// Validate 校验FTP文件系统配置
+func (c FtpDriverConfig) Validate() error {
+	var tlsErr error
+	if c.TLS != "" && c.TLS != "explicit" && c.TLS != "implicit" {
+		tlsErr = fmt.Errorf("tls: unsupported mode %q, expected explicit or implicit", c.TLS)
+	}
+	return errors.Join(required("host", c.Host), tlsErr)
+}
+
+// Validate 校验Azure Blob文件系统配置
+func (c AzblobDriverConfig) Validate() error {
+	var credErr error
+	if c.AccountKey == "" && c.SasToken == "" {
+		credErr = fmt.Errorf("account_key or sas_token: %w", ErrRequired)
+	}
+	return errors.Join(
+		required("account_name", c.AccountName),
+		required("container", c.Container),
+		credErr,
+		validUrl("endpoint", c.Endpoint),
+		validUrl("domain", c.Domain),
+	)
+}
+
+// Validate 校验Google Cloud Storage文件系统配置
+func (c GcsDriverConfig) Validate() error {
+	return errors.Join(
+		required("bucket", c.Bucket),
+		validUrl("endpoint", c.Endpoint),
+		validUrl("domain", c.Domain),
+	)
+}
+
+// Validate 校验压缩包文件系统配置
+func (c ArchiveDriverConfig) Validate() error {
+	return errors.Join(required("path", c.Path), validUrl("base_url", c.BaseUrl))
+}
+
+// Validate 校验内存文件系统配置
+func (c MemoryDriverConfig) Validate() error {
+	return validUrl("base_url", c.BaseUrl)
+}
+
+func required(field, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s: %w", field, ErrRequired)
+	}
+	return nil
+}
+
+// ParseFileMode 解析八进制的权限字符串，为空时返回0
+func ParseFileMode(value string) (os.FileMode, error) {
+	if value == "" {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid file mode %q", value)
+	}
+	return os.FileMode(mode), nil
+}
+
+func wrapField(field string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", field, err)
+}
+
+// validUrl 校验可选的URL字段，为空时不校验
+func validUrl(field, value string) error {
+	if value == "" {
+		return nil
+	}
+	if _, err := url.Parse(value); err != nil {
+		return fmt.Errorf("%s: %w", field, err)
+	}
+	return nil
+}
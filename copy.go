@@ -0,0 +1,59 @@
+package filesystem
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yu1ec/go-filesystem/types"
+)
+
+// CopyBetween 在两个文件系统之间复制文件
+// 同一文件系统时使用驱动自身的 Copy，否则以流的方式读取后写入
+func CopyBetween(ctx context.Context, src Filesystem, srcPath string, dst Filesystem, dstPath string, overwrite bool) error {
+	if src == dst {
+		return src.Copy(ctx, srcPath, dstPath, overwrite)
+	}
+
+	if !overwrite {
+		exists, err := dst.ExistsE(ctx, dstPath)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return types.NewPathError("copy", dstPath, ErrAlreadyExists, nil)
+		}
+	}
+
+	size := int64(-1)
+	if info, err := src.Stat(ctx, srcPath); err == nil {
+		size = info.Size
+	}
+
+	reader, err := src.GetStream(ctx, srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to read source file: %w", err)
+	}
+	defer reader.Close()
+
+	if err := dst.PutStream(ctx, dstPath, reader, size); err != nil {
+		return fmt.Errorf("failed to write destination file: %w", err)
+	}
+	return nil
+}
+
+// MoveBetween 在两个文件系统之间移动文件
+// 同一文件系统时使用驱动自身的 Move，否则复制完成后删除源文件
+func MoveBetween(ctx context.Context, src Filesystem, srcPath string, dst Filesystem, dstPath string, overwrite bool) error {
+	if src == dst {
+		return src.Move(ctx, srcPath, dstPath, overwrite)
+	}
+
+	if err := CopyBetween(ctx, src, srcPath, dst, dstPath, overwrite); err != nil {
+		return err
+	}
+
+	if err := src.DeleteWithContext(ctx, srcPath); err != nil {
+		return fmt.Errorf("failed to delete source file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package filesystem_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/yu1ec/go-filesystem"
+	"github.com/yu1ec/go-filesystem/driver/local"
+)
+
+func TestCopyAndMoveBetween(t *testing.T) {
+	srcDir, err := os.MkdirTemp("", "copy_src")
+	if err != nil {
+		t.Fatalf("无法创建临时目录：%v", err)
+	}
+	defer os.RemoveAll(srcDir)
+	dstDir, err := os.MkdirTemp("", "copy_dst")
+	if err != nil {
+		t.Fatalf("无法创建临时目录：%v", err)
+	}
+	defer os.RemoveAll(dstDir)
+
+	src := local.NewStorage(srcDir, "")
+	dst := local.NewStorage(dstDir, "")
+	data := []byte("跨文件系统复制")
+	if err := src.Put(context.Background(), "a.txt", data); err != nil {
+		t.Fatalf("Put失败：%v", err)
+	}
+
+	if err := filesystem.CopyBetween(context.Background(), src, "a.txt", dst, "b/a.txt", false); err != nil {
+		t.Fatalf("CopyBetween失败：%v", err)
+	}
+	got, err := dst.Get("b/a.txt")
+	if err != nil || string(got) != string(data) {
+		t.Errorf("复制后的数据不匹配：%s, %v", got, err)
+	}
+
+	err = filesystem.CopyBetween(context.Background(), src, "a.txt", dst, "b/a.txt", false)
+	if !errors.Is(err, filesystem.ErrAlreadyExists) {
+		t.Errorf("期望返回ErrAlreadyExists，实际：%v", err)
+	}
+
+	if err := filesystem.MoveBetween(context.Background(), src, "a.txt", dst, "b/a.txt", true); err != nil {
+		t.Fatalf("MoveBetween失败：%v", err)
+	}
+	if src.Exists("a.txt") {
+		t.Error("移动后源文件仍然存在")
+	}
+}
@@ -0,0 +1,18 @@
+// Package all 导入全部可选驱动，导入后即可按名称创建任意驱动
+//
+//	import _ "github.com/yu1ec/go-filesystem/driver/all"
+//
+// local、memory、qiniu 和 webdav 为内置驱动，无需导入
+// 只需要部分驱动时单独匿名导入对应的驱动包，避免引入其他驱动的依赖
+package all
+
+import (
+	_ "github.com/yu1ec/go-filesystem/driver/archive"
+	_ "github.com/yu1ec/go-filesystem/driver/azblob"
+	_ "github.com/yu1ec/go-filesystem/driver/cos"
+	_ "github.com/yu1ec/go-filesystem/driver/ftp"
+	_ "github.com/yu1ec/go-filesystem/driver/gcs"
+	_ "github.com/yu1ec/go-filesystem/driver/oss"
+	_ "github.com/yu1ec/go-filesystem/driver/s3"
+	_ "github.com/yu1ec/go-filesystem/driver/sftp"
+)
@@ -0,0 +1,435 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"mime"
+	"os"
+	pathpkg "path"
+	"strings"
+	"time"
+
+	"github.com/yu1ec/go-filesystem/internal/fsutil"
+	"github.com/yu1ec/go-filesystem/types"
+)
+
+// Source 压缩包所在的文件系统，任意 Filesystem 都满足此接口
+type Source interface {
+	GetStream(ctx context.Context, path string) (io.ReadCloser, error)
+}
+
+// ArchiveFilesystem 只读的压缩包文件系统，支持 zip 和 tar(.gz)
+// 打开时建立文件索引，写入、复制、移动和删除均返回 ErrReadOnly
+type ArchiveFilesystem struct {
+	BaseUrl string // 基础URL
+
+	entries map[string]*entry
+	closer  io.Closer
+}
+
+type entry struct {
+	size    int64
+	modTime time.Time
+	open    func() (io.ReadCloser, error)
+}
+
+// NewStorage 打开本地的压缩包
+// 根据扩展名判断格式，支持 .zip、.tar、.tar.gz 和 .tgz
+// zip 和 tar 按需从压缩包读取文件内容，.tar.gz 先解压到临时文件，关闭时删除
+func NewStorage(archivePath, baseUrl string) (*ArchiveFilesystem, error) {
+	fs := &ArchiveFilesystem{BaseUrl: baseUrl, entries: make(map[string]*entry)}
+
+	switch kind := format(archivePath); kind {
+	case "zip":
+		r, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		fs.closer = r
+		fs.indexZip(&r.Reader)
+	case "tar":
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		if err := fs.indexTar(f); err != nil {
+			f.Close()
+			return nil, err
+		}
+		fs.closer = f
+	case "tgz":
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if err := fs.indexSpooled(f, kind); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported archive format: %s", archivePath)
+	}
+	return fs, nil
+}
+
+// NewStorageFromSource 从其他文件系统读取压缩包
+// 压缩包先写入临时文件（.tar.gz 写入解压后的内容），不会整个读入内存，关闭时删除临时文件
+func NewStorageFromSource(ctx context.Context, src Source, archivePath, baseUrl string) (*ArchiveFilesystem, error) {
+	fs := &ArchiveFilesystem{BaseUrl: baseUrl, entries: make(map[string]*entry)}
+
+	kind := format(archivePath)
+	if kind == "" {
+		return nil, fmt.Errorf("unsupported archive format: %s", archivePath)
+	}
+
+	body, err := src.GetStream(ctx, archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	if err := fs.indexSpooled(body, kind); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// indexSpooled 将压缩包写入临时文件后建立索引，.tar.gz 写入解压后的 tar
+func (fs *ArchiveFilesystem) indexSpooled(r io.Reader, kind string) error {
+	if kind == "tgz" {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	f, err := os.CreateTemp("", "go-filesystem-archive-*")
+	if err != nil {
+		return err
+	}
+	tmp := &tempFile{f}
+	size, err := io.Copy(f, r)
+	if err == nil && kind == "zip" {
+		var zr *zip.Reader
+		if zr, err = zip.NewReader(f, size); err == nil {
+			fs.indexZip(zr)
+		}
+	} else if err == nil {
+		err = fs.indexTar(f)
+	}
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	fs.closer = tmp
+	return nil
+}
+
+// tempFile 关闭时删除的临时文件
+type tempFile struct {
+	*os.File
+}
+
+func (f *tempFile) Close() error {
+	err := f.File.Close()
+	if removeErr := os.Remove(f.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// indexZip 记录zip中的文件，忽略目录
+func (fs *ArchiveFilesystem) indexZip(r *zip.Reader) {
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		fs.entries[cleanPath(f.Name)] = &entry{
+			size:    int64(f.UncompressedSize64),
+			modTime: f.Modified,
+			open:    f.Open,
+		}
+	}
+}
+
+// indexTar 记录未压缩的tar中的普通文件，忽略目录和链接
+// 只读取文件头并记录内容的偏移量，读取时通过 SectionReader 直接读取 f
+func (fs *ArchiveFilesystem) indexTar(f *os.File) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(f)
+	for i := 0; ; i++ {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		e := &entry{size: header.Size, modTime: header.ModTime}
+		if isSparse(header) {
+			// 稀疏文件的内容需要经过 tar.Reader 还原，读取时重新定位到该文件
+			index := i
+			e.open = func() (io.ReadCloser, error) {
+				return openTarEntry(f, index)
+			}
+		} else {
+			offset, err := f.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return err
+			}
+			e.open = func() (io.ReadCloser, error) {
+				return io.NopCloser(io.NewSectionReader(f, offset, header.Size)), nil
+			}
+		}
+		fs.entries[cleanPath(header.Name)] = e
+	}
+}
+
+// isSparse 判断是否为 PAX 格式的 GNU 稀疏文件
+func isSparse(header *tar.Header) bool {
+	for key := range header.PAXRecords {
+		if strings.HasPrefix(key, "GNU.sparse.") {
+			return true
+		}
+	}
+	return false
+}
+
+// openTarEntry 从头读取tar的文件头，返回第 index 个文件的内容
+// 使用独立的 SectionReader，可以与其他读取并发
+func openTarEntry(f *os.File, index int) (io.ReadCloser, error) {
+	tr := tar.NewReader(io.NewSectionReader(f, 0, math.MaxInt64))
+	for i := 0; i <= index; i++ {
+		if _, err := tr.Next(); err != nil {
+			return nil, err
+		}
+	}
+	return io.NopCloser(tr), nil
+}
+
+// Put 压缩包只读，返回 ErrReadOnly
+func (fs *ArchiveFilesystem) Put(ctx context.Context, path string, data []byte) error {
+	return types.NewPathError("put", path, types.ErrReadOnly, nil)
+}
+
+func (fs *ArchiveFilesystem) PutWithoutContext(path string, data []byte) error {
+	return fs.Put(context.Background(), path, data)
+}
+
+// PutStream 压缩包只读，返回 ErrReadOnly
+func (fs *ArchiveFilesystem) PutStream(ctx context.Context, path string, reader io.Reader, size int64) error {
+	return fs.Put(ctx, path, nil)
+}
+
+func (fs *ArchiveFilesystem) Get(path string) ([]byte, error) {
+	return fs.GetWithContext(context.Background(), path)
+}
+
+// GetWithContext 获取文件内容
+func (fs *ArchiveFilesystem) GetWithContext(ctx context.Context, path string) ([]byte, error) {
+	body, err := fs.GetStream(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, types.NewPathError("get", path, nil, err)
+	}
+	return data, nil
+}
+
+// GetStream 以流的方式读取文件
+func (fs *ArchiveFilesystem) GetStream(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	e, ok := fs.entries[cleanPath(path)]
+	if !ok {
+		return nil, types.NewPathError("get", path, types.ErrNotFound, nil)
+	}
+	body, err := e.open()
+	if err != nil {
+		return nil, types.NewPathError("get", path, nil, err)
+	}
+	return body, nil
+}
+
+// GetUrl 获取文件的URL
+func (fs *ArchiveFilesystem) GetUrl(path string) string {
+	return strings.TrimRight(fs.BaseUrl, "/") + "/" + cleanPath(path)
+}
+
+// GetSignedUrl 压缩包不支持签名，直接返回URL
+func (fs *ArchiveFilesystem) GetSignedUrl(path string, expires int64) (string, error) {
+	return fs.GetUrl(path), nil
+}
+
+// MustGetSignedUrl 获取签名URL
+func (fs *ArchiveFilesystem) MustGetSignedUrl(path string, expires int64) string {
+	url, err := fs.GetSignedUrl(path, expires)
+	if err != nil {
+		panic(err)
+	}
+	return url
+}
+
+func (fs *ArchiveFilesystem) GetImageWidthHeight(path string) (int, int, error) {
+	return fs.GetImageWidthHeightWithContext(context.Background(), path)
+}
+
+// GetImageWidthHeightWithContext 获取图片的宽高
+func (fs *ArchiveFilesystem) GetImageWidthHeightWithContext(ctx context.Context, path string) (int, int, error) {
+	body, err := fs.GetStream(ctx, path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer body.Close()
+
+	cfg, _, err := image.DecodeConfig(body)
+	if err != nil {
+		return 0, 0, err
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// Stat 获取文件信息 ETag 由修改时间和大小生成
+func (fs *ArchiveFilesystem) Stat(ctx context.Context, path string) (types.FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return types.FileInfo{}, err
+	}
+
+	e, ok := fs.entries[cleanPath(path)]
+	if !ok {
+		return types.FileInfo{}, types.NewPathError("stat", path, types.ErrNotFound, nil)
+	}
+	return e.info(path), nil
+}
+
+// List 列举目录下的文件
+// 只记录了文件，非递归时根据文件路径推断子目录
+func (fs *ArchiveFilesystem) List(ctx context.Context, prefix string, opts types.ListOptions) (types.ListResult, error) {
+	if err := ctx.Err(); err != nil {
+		return types.ListResult{}, err
+	}
+
+	dir := cleanPath(prefix)
+	if dir != "" {
+		dir += "/"
+	}
+
+	var files []types.FileInfo
+	dirs := make(map[string]bool)
+	for key, e := range fs.entries {
+		if !strings.HasPrefix(key, dir) {
+			continue
+		}
+
+		rel := strings.TrimPrefix(key, dir)
+		if i := strings.Index(rel, "/"); i >= 0 && !opts.Recursive {
+			dirs[dir+rel[:i+1]] = true
+			continue
+		}
+		files = append(files, e.info(key))
+	}
+	for d := range dirs {
+		files = append(files, types.FileInfo{Path: d, IsDir: true})
+	}
+
+	return types.Paginate(files, opts.Cursor, opts.Limit), nil
+}
+
+// Copy 压缩包只读，返回 ErrReadOnly
+func (fs *ArchiveFilesystem) Copy(ctx context.Context, src, dst string, overwrite bool) error {
+	return types.NewPathError("copy", dst, types.ErrReadOnly, nil)
+}
+
+// Move 压缩包只读，返回 ErrReadOnly
+func (fs *ArchiveFilesystem) Move(ctx context.Context, src, dst string, overwrite bool) error {
+	return types.NewPathError("move", src, types.ErrReadOnly, nil)
+}
+
+// Delete 压缩包只读，返回 ErrReadOnly
+func (fs *ArchiveFilesystem) Delete(path string) error {
+	return fs.DeleteWithContext(context.Background(), path)
+}
+
+// DeleteWithContext 压缩包只读，返回 ErrReadOnly
+func (fs *ArchiveFilesystem) DeleteWithContext(ctx context.Context, path string) error {
+	return types.NewPathError("delete", path, types.ErrReadOnly, nil)
+}
+
+// Exists 判断文件是否存在
+func (fs *ArchiveFilesystem) Exists(path string) bool {
+	return fs.ExistsWithContext(context.Background(), path)
+}
+
+// ExistsWithContext 判断文件是否存在 无法判断时返回 false，需要区分时使用 ExistsE
+func (fs *ArchiveFilesystem) ExistsWithContext(ctx context.Context, path string) bool {
+	exists, _ := fs.ExistsE(ctx, path)
+	return exists
+}
+
+// ExistsE 判断文件是否存在 只在 ctx 取消时返回错误
+func (fs *ArchiveFilesystem) ExistsE(ctx context.Context, path string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	_, ok := fs.entries[cleanPath(path)]
+	return ok, nil
+}
+
+// Close 关闭压缩包并删除临时文件
+func (fs *ArchiveFilesystem) Close() error {
+	if fs.closer != nil {
+		return fs.closer.Close()
+	}
+	return nil
+}
+
+func (e *entry) info(path string) types.FileInfo {
+	return types.FileInfo{
+		Path:         path,
+		Size:         e.size,
+		LastModified: e.modTime,
+		ContentType:  mime.TypeByExtension(pathpkg.Ext(path)),
+		ETag:         fsutil.ETag(e.modTime, e.size),
+	}
+}
+
+// format 根据扩展名判断压缩包格式，不支持时返回空字符串
+func format(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return "zip"
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return "tgz"
+	case strings.HasSuffix(name, ".tar"):
+		return "tar"
+	}
+	return ""
+}
+
+// cleanPath 统一文件路径，去掉开头的 / 并处理 . 和 ..
+// 压缩包中的 ../ 也会被限制在根目录内
+func cleanPath(path string) string {
+	return strings.TrimPrefix(pathpkg.Clean("/"+path), "/")
+}
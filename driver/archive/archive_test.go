@@ -0,0 +1,308 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yu1ec/go-filesystem/driver/archive"
+	"github.com/yu1ec/go-filesystem/driver/memory"
+	"github.com/yu1ec/go-filesystem/internal/drivertest"
+	"github.com/yu1ec/go-filesystem/types"
+)
+
+// testFiles 返回压缩包中的测试文件
+func testFiles(t *testing.T) map[string][]byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 100, 50))); err != nil {
+		t.Fatalf("无法编码图片：%v", err)
+	}
+	return map[string][]byte{
+		"index.html":            []byte("<h1>首页</h1>"),
+		"static/app.js":         []byte("console.log(1)"),
+		"static/img/logo.png":   buf.Bytes(),
+		"static/img/banner.txt": []byte("横幅"),
+	}
+}
+
+func sortedNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func buildZip(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	w.Create("static/")
+	for _, name := range sortedNames(files) {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write(files[name])
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func buildTar(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	w.WriteHeader(&tar.Header{Name: "static/", Typeflag: tar.TypeDir, Mode: 0755})
+	w.WriteHeader(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "index.html"})
+	for _, name := range sortedNames(files) {
+		w.WriteHeader(&tar.Header{Name: "./" + name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(files[name])), ModTime: time.Now()})
+		w.Write(files[name])
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func buildTarGz(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(buildTar(t, files))
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestArchiveFilesystem(t *testing.T) {
+	files := testFiles(t)
+	dir := t.TempDir()
+	archives := map[string][]byte{
+		"bundle.zip":    buildZip(t, files),
+		"bundle.tar":    buildTar(t, files),
+		"bundle.tar.gz": buildTarGz(t, files),
+	}
+
+	for name, data := range archives {
+		archivePath := filepath.Join(dir, name)
+		if err := os.WriteFile(archivePath, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run(name, func(t *testing.T) {
+			fs, err := archive.NewStorage(archivePath, "http://example.com/")
+			if err != nil {
+				t.Fatalf("NewStorage失败：%v", err)
+			}
+			defer fs.Close()
+			testArchive(t, fs, files)
+		})
+	}
+}
+
+func testArchive(t *testing.T, fs *archive.ArchiveFilesystem, files map[string][]byte) {
+	ctx := context.Background()
+
+	t.Run("Get", func(t *testing.T) {
+		for name, expected := range files {
+			data, err := fs.Get("/" + name)
+			if err != nil {
+				t.Fatalf("Get %s 失败：%v", name, err)
+			}
+			if !bytes.Equal(data, expected) {
+				t.Errorf("%s 的内容不匹配", name)
+			}
+		}
+		if _, err := fs.Get("missing.txt"); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("期望 ErrNotFound，实际：%v", err)
+		}
+	})
+
+	t.Run("Exists", func(t *testing.T) {
+		if !fs.Exists("static/app.js") {
+			t.Error("文件应该存在")
+		}
+		if exists, err := fs.ExistsE(ctx, "static"); exists || err != nil {
+			t.Errorf("目录不应视为文件：%v", err)
+		}
+		if fs.Exists("link") {
+			t.Error("链接不应被读取")
+		}
+	})
+
+	t.Run("GetImageWidthHeight", func(t *testing.T) {
+		width, height, err := fs.GetImageWidthHeight("static/img/logo.png")
+		if err != nil {
+			t.Fatalf("GetImageWidthHeight失败：%v", err)
+		}
+		if width != 100 || height != 50 {
+			t.Errorf("图片尺寸不匹配。期望：100x50，实际：%dx%d", width, height)
+		}
+	})
+
+	t.Run("Stat", func(t *testing.T) {
+		info, err := fs.Stat(ctx, "static/img/logo.png")
+		if err != nil {
+			t.Fatalf("Stat失败：%v", err)
+		}
+		if info.ContentType != "image/png" || info.Size != int64(len(files["static/img/logo.png"])) || info.ETag == "" || info.LastModified.IsZero() {
+			t.Errorf("文件信息不正确：%+v", info)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		result, err := fs.List(ctx, "", types.ListOptions{})
+		if err != nil {
+			t.Fatalf("List失败：%v", err)
+		}
+		if got := drivertest.ListPaths(result.Files); got != "index.html,static/" {
+			t.Errorf("期望 index.html,static/，实际 %s", got)
+		}
+
+		var all []types.FileInfo
+		opts := types.ListOptions{Recursive: true, Limit: 2}
+		for {
+			result, err := fs.List(ctx, "static", opts)
+			if err != nil {
+				t.Fatalf("List失败：%v", err)
+			}
+			all = append(all, result.Files...)
+			if result.NextCursor == "" {
+				break
+			}
+			opts.Cursor = result.NextCursor
+		}
+		expected := "static/app.js,static/img/banner.txt,static/img/logo.png"
+		if got := drivertest.ListPaths(all); got != expected {
+			t.Errorf("期望 %s，实际 %s", expected, got)
+		}
+	})
+
+	t.Run("GetUrl", func(t *testing.T) {
+		if url := fs.GetUrl("/static/app.js"); url != "http://example.com/static/app.js" {
+			t.Errorf("URL不正确：%s", url)
+		}
+	})
+
+	t.Run("只读", func(t *testing.T) {
+		if err := fs.Put(ctx, "new.txt", []byte("x")); !errors.Is(err, types.ErrReadOnly) {
+			t.Errorf("Put期望 ErrReadOnly，实际：%v", err)
+		}
+		if err := fs.PutStream(ctx, "new.txt", strings.NewReader("x"), 1); !errors.Is(err, types.ErrReadOnly) {
+			t.Errorf("PutStream期望 ErrReadOnly，实际：%v", err)
+		}
+		if err := fs.Delete("index.html"); !errors.Is(err, types.ErrReadOnly) {
+			t.Errorf("Delete期望 ErrReadOnly，实际：%v", err)
+		}
+		if err := fs.Copy(ctx, "index.html", "copy.html", true); !errors.Is(err, types.ErrReadOnly) {
+			t.Errorf("Copy期望 ErrReadOnly，实际：%v", err)
+		}
+		if err := fs.Move(ctx, "index.html", "moved.html", true); !errors.Is(err, types.ErrReadOnly) {
+			t.Errorf("Move期望 ErrReadOnly，实际：%v", err)
+		}
+		if !fs.Exists("index.html") {
+			t.Error("只读操作后文件应该仍然存在")
+		}
+	})
+}
+
+func TestArchiveFilesystem_FromSource(t *testing.T) {
+	files := testFiles(t)
+	src := memory.NewStorage("")
+	ctx := context.Background()
+	src.Put(ctx, "bundles/site.zip", buildZip(t, files))
+	src.Put(ctx, "bundles/site.tar", buildTar(t, files))
+	src.Put(ctx, "bundles/site.tgz", buildTarGz(t, files))
+
+	// 压缩包写入临时文件，关闭后应删除
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	for _, name := range []string{"bundles/site.zip", "bundles/site.tar", "bundles/site.tgz"} {
+		t.Run(name, func(t *testing.T) {
+			fs, err := archive.NewStorageFromSource(ctx, src, name, "")
+			if err != nil {
+				t.Fatalf("NewStorageFromSource失败：%v", err)
+			}
+			if data, err := fs.Get("static/app.js"); err != nil || string(data) != "console.log(1)" {
+				t.Errorf("Get失败：%s %v", string(data), err)
+			}
+			if err := fs.Close(); err != nil {
+				t.Errorf("Close失败：%v", err)
+			}
+			if tmp, _ := os.ReadDir(tmpDir); len(tmp) != 0 {
+				t.Errorf("关闭后临时文件应该被删除：%d", len(tmp))
+			}
+		})
+	}
+
+	if _, err := archive.NewStorageFromSource(ctx, src, "bundles/missing.zip", ""); !errors.Is(err, types.ErrNotFound) {
+		t.Errorf("期望 ErrNotFound，实际：%v", err)
+	}
+}
+
+func TestArchiveFilesystem_SparseTar(t *testing.T) {
+	// tar.Writer 不能写入稀疏文件，先写入带注释的 PAX 头，再替换为等长的 GNU 稀疏记录
+	// 稀疏映射 3,3 表示前 3 字节为空洞，随后是存储的 abc
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	w.WriteHeader(&tar.Header{Name: "before.txt", Typeflag: tar.TypeReg, Size: 6, Mode: 0644})
+	w.Write([]byte("before"))
+	comment := "69 comment=" + strings.Repeat("x", 57) + "\n"
+	w.WriteHeader(&tar.Header{Name: "sparse.bin", Typeflag: tar.TypeReg, Size: 3, Mode: 0644, Format: tar.FormatPAX, PAXRecords: map[string]string{"comment": strings.Repeat("x", 57)}})
+	w.Write([]byte("abc"))
+	w.WriteHeader(&tar.Header{Name: "after.txt", Typeflag: tar.TypeReg, Size: 5, Mode: 0644})
+	w.Write([]byte("after"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Replace(buf.Bytes(), []byte(comment), []byte("22 GNU.sparse.map=3,3\n26 GNU.sparse.numblocks=1\n21 GNU.sparse.size=6\n"), 1)
+	if bytes.Equal(data, buf.Bytes()) {
+		t.Fatal("未找到注释记录")
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "sparse.tar")
+	if err := os.WriteFile(archivePath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	fs, err := archive.NewStorage(archivePath, "")
+	if err != nil {
+		t.Fatalf("NewStorage失败：%v", err)
+	}
+	defer fs.Close()
+
+	expected := map[string]string{"before.txt": "before", "sparse.bin": "\x00\x00\x00abc", "after.txt": "after"}
+	for name, content := range expected {
+		if got, err := fs.Get(name); err != nil || string(got) != content {
+			t.Errorf("%s 的内容不匹配：%q %v", name, got, err)
+		}
+	}
+	if info, _ := fs.Stat(context.Background(), "sparse.bin"); info.Size != 6 {
+		t.Errorf("稀疏文件大小不正确：%d", info.Size)
+	}
+}
+
+func TestArchiveFilesystem_InvalidArchive(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := archive.NewStorage(filepath.Join(dir, "bundle.rar"), ""); err == nil {
+		t.Error("不支持的格式应该返回错误")
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.zip")
+	os.WriteFile(corrupt, []byte("not a zip"), 0644)
+	if _, err := archive.NewStorage(corrupt, ""); err == nil {
+		t.Error("损坏的压缩包应该返回错误")
+	}
+}
@@ -0,0 +1,20 @@
+package archive
+
+import (
+	filesystem "github.com/yu1ec/go-filesystem"
+	"github.com/yu1ec/go-filesystem/config"
+)
+
+func init() {
+	filesystem.RegisterDriver("archive", func(cfg any) (filesystem.Filesystem, error) {
+		var c config.ArchiveDriverConfig
+		if err := filesystem.DecodeConfig(cfg, &c); err != nil {
+			return nil, err
+		}
+		fs, err := NewStorage(c.Path, c.BaseUrl)
+		if err != nil {
+			return nil, err
+		}
+		return fs, nil
+	})
+}
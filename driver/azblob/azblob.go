@@ -0,0 +1,426 @@
+package azblob
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+	"github.com/yu1ec/go-filesystem/internal/objstore"
+	"github.com/yu1ec/go-filesystem/types"
+)
+
+// DefaultBlockSize 默认分块大小，Azure 限制一个块Blob最多 50000 个分块
+const DefaultBlockSize = objstore.DefaultPartSize
+
+// copyPollInterval 服务端复制未完成时查询状态的间隔
+const copyPollInterval = 500 * time.Millisecond
+
+type AzblobFilesystem struct {
+	AccountName string
+	Container   Container
+
+	client *container.Client
+}
+
+// Container 容器
+type Container struct {
+	Name      string // 容器名称
+	Endpoint  string // 服务地址 为空时使用 https://<AccountName>.blob.core.windows.net
+	SasToken  string // SAS令牌 未提供账号密钥时使用，此时无法生成签名URL
+	Domain    string // 访问域名 如CDN域名，为空时使用容器地址
+	BlockSize int64  // 分块大小 超过此大小的文件分块上传，小于等于0时使用 DefaultBlockSize
+}
+
+// NewStorage 创建Azure Blob存储
+// accountKey 为空时使用 Container.SasToken 访问
+func NewStorage(accountName, accountKey string, c Container) (*AzblobFilesystem, error) {
+	endpoint := strings.TrimRight(c.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = "https://" + accountName + ".blob.core.windows.net"
+	}
+	containerUrl := endpoint + "/" + url.PathEscape(c.Name)
+
+	var client *container.Client
+	var err error
+	if accountKey != "" {
+		cred, credErr := container.NewSharedKeyCredential(accountName, accountKey)
+		if credErr != nil {
+			return nil, fmt.Errorf("invalid account key: %w", credErr)
+		}
+		client, err = container.NewClientWithSharedKeyCredential(containerUrl, cred, nil)
+	} else {
+		if c.SasToken != "" {
+			containerUrl += "?" + strings.TrimPrefix(c.SasToken, "?")
+		}
+		client, err = container.NewClientWithNoCredential(containerUrl, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &AzblobFilesystem{
+		AccountName: accountName,
+		Container:   c,
+		client:      client,
+	}, nil
+}
+
+func (fs *AzblobFilesystem) Put(ctx context.Context, path string, data []byte) error {
+	return fs.PutStream(ctx, path, bytes.NewReader(data), int64(len(data)))
+}
+
+func (fs *AzblobFilesystem) PutWithoutContext(path string, data []byte) error {
+	return fs.Put(context.Background(), path, data)
+}
+
+// PutStream 以流的方式写入文件
+// 数据不超过分块大小时直接上传，否则逐块暂存后提交块列表，内存占用不超过一个分块
+func (fs *AzblobFilesystem) PutStream(ctx context.Context, path string, reader io.Reader, size int64) error {
+	return convertError("put", path, objstore.PutStream(ctx, uploader{fs}, objstore.Key(path), reader, size, fs.Container.BlockSize))
+}
+
+// uploader 实现 objstore.Uploader
+type uploader struct {
+	fs *AzblobFilesystem
+}
+
+func (u uploader) PutObject(ctx context.Context, key, contentType string, data []byte) error {
+	_, err := u.fs.client.NewBlockBlobClient(key).Upload(ctx, streaming.NopCloser(bytes.NewReader(data)), &blockblob.UploadOptions{
+		HTTPHeaders: &blob.HTTPHeaders{BlobContentType: &contentType},
+	})
+	return err
+}
+
+func (u uploader) CreateMultipart(ctx context.Context, key, contentType string) (objstore.Multipart, error) {
+	return &blocks{
+		client:  u.fs.client.NewBlockBlobClient(key),
+		headers: &blob.HTTPHeaders{BlobContentType: &contentType},
+	}, nil
+}
+
+// blocks 逐块暂存后提交块列表
+type blocks struct {
+	client   *blockblob.Client
+	headers  *blob.HTTPHeaders
+	blockIds []string
+}
+
+func (b *blocks) UploadPart(ctx context.Context, partNumber int, data []byte) error {
+	// 同一个Blob的分块ID长度必须相同
+	blockId := base64.StdEncoding.EncodeToString(fmt.Appendf(nil, "%08d", partNumber-1))
+	if _, err := b.client.StageBlock(ctx, blockId, streaming.NopCloser(bytes.NewReader(data)), nil); err != nil {
+		return err
+	}
+	b.blockIds = append(b.blockIds, blockId)
+	return nil
+}
+
+func (b *blocks) Complete(ctx context.Context) error {
+	_, err := b.client.CommitBlockList(ctx, b.blockIds, &blockblob.CommitBlockListOptions{HTTPHeaders: b.headers})
+	return err
+}
+
+// Abort 未提交的分块会在一周后被服务端自动清理，因此不需要额外处理
+func (b *blocks) Abort(ctx context.Context) error {
+	return nil
+}
+
+func (fs *AzblobFilesystem) Get(path string) ([]byte, error) {
+	return fs.GetWithContext(context.Background(), path)
+}
+
+// GetWithContext 获取文件内容
+func (fs *AzblobFilesystem) GetWithContext(ctx context.Context, path string) ([]byte, error) {
+	body, err := fs.GetStream(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, types.NewPathError("get", path, nil, err)
+	}
+	return data, nil
+}
+
+// GetStream 以流的方式读取文件
+func (fs *AzblobFilesystem) GetStream(ctx context.Context, path string) (io.ReadCloser, error) {
+	resp, err := fs.client.NewBlobClient(objstore.Key(path)).DownloadStream(ctx, nil)
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+	return resp.Body, nil
+}
+
+// GetStreamRange 从 offset 开始读取 length 字节，length 小于等于0时读取到文件末尾
+func (fs *AzblobFilesystem) GetStreamRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	// Count 为0时读取到末尾
+	resp, err := fs.client.NewBlobClient(objstore.Key(path)).DownloadStream(ctx, &blob.DownloadStreamOptions{
+		Range: blob.HTTPRange{Offset: offset, Count: max(length, 0)},
+	})
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+	return resp.Body, nil
+}
+
+// GetUrl 获取文件的URL
+// 未设置 Domain 时使用容器地址，不包含SAS令牌
+func (fs *AzblobFilesystem) GetUrl(path string) string {
+	key := objstore.EscapeKey(objstore.Key(path))
+	if fs.Container.Domain != "" {
+		return strings.TrimRight(fs.Container.Domain, "/") + "/" + key
+	}
+	u, _ := url.Parse(fs.client.URL())
+	u.RawQuery = ""
+	return u.String() + "/" + key
+}
+
+// GetSignedUrl 获取签名URL 生成只读的SAS令牌
+// path: 文件路径
+// expires: 过期时间 单位/秒
+// 只有使用账号密钥时才能生成
+func (fs *AzblobFilesystem) GetSignedUrl(path string, expires int64) (string, error) {
+	expiry := time.Now().Add(time.Duration(expires) * time.Second)
+	u, err := fs.client.NewBlobClient(objstore.Key(path)).GetSASURL(sas.BlobPermissions{Read: true}, expiry, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate sas url: %w", err)
+	}
+	return u, nil
+}
+
+// MustGetSignedUrl 获取签名URL
+func (fs *AzblobFilesystem) MustGetSignedUrl(path string, expires int64) string {
+	url, err := fs.GetSignedUrl(path, expires)
+	if err != nil {
+		panic(err)
+	}
+	return url
+}
+
+func (fs *AzblobFilesystem) GetImageWidthHeight(path string) (int, int, error) {
+	return fs.GetImageWidthHeightWithContext(context.Background(), path)
+}
+
+// GetImageWidthHeightWithContext 获取图片的宽高
+// Azure 没有图片处理服务，只读取解析图片头所需的数据
+func (fs *AzblobFilesystem) GetImageWidthHeightWithContext(ctx context.Context, path string) (int, int, error) {
+	body, err := fs.GetStream(ctx, path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer body.Close()
+
+	cfg, _, err := image.DecodeConfig(body)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// Stat 获取文件信息 通过 Get Blob Properties 获取
+func (fs *AzblobFilesystem) Stat(ctx context.Context, path string) (types.FileInfo, error) {
+	resp, err := fs.client.NewBlobClient(objstore.Key(path)).GetProperties(ctx, nil)
+	if err != nil {
+		return types.FileInfo{}, convertError("stat", path, err)
+	}
+
+	return types.FileInfo{
+		Path:         path,
+		Size:         deref(resp.ContentLength),
+		LastModified: deref(resp.LastModified),
+		ContentType:  deref(resp.ContentType),
+		ETag:         string(deref(resp.ETag)),
+	}, nil
+}
+
+// List 列举目录下的文件
+// 游标为Azure返回的 NextMarker
+func (fs *AzblobFilesystem) List(ctx context.Context, prefix string, opts types.ListOptions) (types.ListResult, error) {
+	prefix = objstore.Key(prefix)
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	marker := optional(opts.Cursor)
+	var maxResults *int32
+	if opts.Limit > 0 && opts.Limit < 5000 {
+		limit := int32(opts.Limit)
+		maxResults = &limit
+	}
+
+	result := types.ListResult{}
+	var items []*container.BlobItem
+	var nextMarker *string
+	if opts.Recursive {
+		resp, err := fs.client.NewListBlobsFlatPager(&container.ListBlobsFlatOptions{
+			Prefix: &prefix, Marker: marker, MaxResults: maxResults,
+		}).NextPage(ctx)
+		if err != nil {
+			return types.ListResult{}, convertError("list", prefix, err)
+		}
+		items, nextMarker = resp.Segment.BlobItems, resp.NextMarker
+	} else {
+		resp, err := fs.client.NewListBlobsHierarchyPager("/", &container.ListBlobsHierarchyOptions{
+			Prefix: &prefix, Marker: marker, MaxResults: maxResults,
+		}).NextPage(ctx)
+		if err != nil {
+			return types.ListResult{}, convertError("list", prefix, err)
+		}
+		for _, dir := range resp.Segment.BlobPrefixes {
+			result.Files = append(result.Files, types.FileInfo{Path: deref(dir.Name), IsDir: true})
+		}
+		items, nextMarker = resp.Segment.BlobItems, resp.NextMarker
+	}
+
+	for _, item := range items {
+		info := types.FileInfo{Path: deref(item.Name)}
+		if props := item.Properties; props != nil {
+			info.Size = deref(props.ContentLength)
+			info.LastModified = deref(props.LastModified)
+			info.ContentType = deref(props.ContentType)
+			info.ETag = string(deref(props.ETag))
+		}
+		result.Files = append(result.Files, info)
+	}
+	result.NextCursor = deref(nextMarker)
+	return result, nil
+}
+
+// Copy 复制文件 使用Azure的服务端复制
+func (fs *AzblobFilesystem) Copy(ctx context.Context, src, dst string, overwrite bool) error {
+	return fs.copy(ctx, "copy", src, dst, overwrite)
+}
+
+// Move 移动文件 Azure不支持移动，使用服务端复制后删除源文件
+func (fs *AzblobFilesystem) Move(ctx context.Context, src, dst string, overwrite bool) error {
+	if err := fs.copy(ctx, "move", src, dst, overwrite); err != nil {
+		return err
+	}
+	_, err := fs.client.NewBlobClient(objstore.Key(src)).Delete(ctx, nil)
+	return convertError("move", src, err)
+}
+
+// copy 不覆盖时使用 If-None-Match: * 条件，由服务端保证目标文件不存在
+// 大文件的复制是异步的，等待复制完成后返回
+func (fs *AzblobFilesystem) copy(ctx context.Context, op, src, dst string, overwrite bool) error {
+	options := &blob.StartCopyFromURLOptions{}
+	if !overwrite {
+		etagAny := azcore.ETagAny
+		options.AccessConditions = &blob.AccessConditions{
+			ModifiedAccessConditions: &blob.ModifiedAccessConditions{IfNoneMatch: &etagAny},
+		}
+	}
+
+	// 同一账号内复制时，源地址使用账号密钥或与客户端相同的SAS令牌授权
+	client := fs.client.NewBlobClient(objstore.Key(dst))
+	resp, err := client.StartCopyFromURL(ctx, fs.client.NewBlobClient(objstore.Key(src)).URL(), options)
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.BlobAlreadyExists, bloberror.ConditionNotMet) {
+			return types.NewPathError(op, dst, types.ErrAlreadyExists, err)
+		}
+		return convertError(op, src, err)
+	}
+
+	status := deref(resp.CopyStatus)
+	for status == blob.CopyStatusTypePending {
+		select {
+		case <-ctx.Done():
+			return types.NewPathError(op, src, nil, ctx.Err())
+		case <-time.After(copyPollInterval):
+		}
+		props, err := client.GetProperties(ctx, nil)
+		if err != nil {
+			return convertError(op, dst, err)
+		}
+		status = deref(props.CopyStatus)
+	}
+	if status != "" && status != blob.CopyStatusTypeSuccess {
+		return types.NewPathError(op, src, nil, fmt.Errorf("copy %s", strings.ToLower(string(status))))
+	}
+	return nil
+}
+
+// Delete 删除文件
+func (fs *AzblobFilesystem) Delete(path string) error {
+	return fs.DeleteWithContext(context.Background(), path)
+}
+
+// DeleteWithContext 删除文件
+func (fs *AzblobFilesystem) DeleteWithContext(ctx context.Context, path string) error {
+	_, err := fs.client.NewBlobClient(objstore.Key(path)).Delete(ctx, nil)
+	return convertError("delete", path, err)
+}
+
+// Exists 判断文件是否存在
+func (fs *AzblobFilesystem) Exists(path string) bool {
+	return fs.ExistsWithContext(context.Background(), path)
+}
+
+// ExistsWithContext 判断文件是否存在 无法判断时返回 false，需要区分时使用 ExistsE
+func (fs *AzblobFilesystem) ExistsWithContext(ctx context.Context, path string) bool {
+	exists, _ := fs.ExistsE(ctx, path)
+	return exists
+}
+
+// ExistsE 判断文件是否存在
+// 文件不存在时返回 false 和 nil，认证失败、网络错误等无法判断的情况返回错误
+func (fs *AzblobFilesystem) ExistsE(ctx context.Context, path string) (bool, error) {
+	_, err := fs.Stat(ctx, path)
+	if errors.Is(err, types.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
+
+// convertError 将Azure返回的错误转换为通用错误
+func convertError(op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		switch {
+		case bloberror.HasCode(err, bloberror.BlobNotFound, bloberror.ContainerNotFound) || respErr.StatusCode == http.StatusNotFound:
+			return types.NewPathError(op, path, types.ErrNotFound, err)
+		case respErr.StatusCode == http.StatusUnauthorized || respErr.StatusCode == http.StatusForbidden:
+			return types.NewPathError(op, path, types.ErrPermission, err)
+		}
+	}
+	return types.NewPathError(op, path, nil, err)
+}
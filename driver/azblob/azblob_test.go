@@ -0,0 +1,327 @@
+package azblob_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yu1ec/go-filesystem/driver/azblob"
+	"github.com/yu1ec/go-filesystem/internal/drivertest"
+	"github.com/yu1ec/go-filesystem/types"
+)
+
+const (
+	testAccount   = "testaccount"
+	testContainer = "test-container"
+)
+
+// fakeBlob 简单的Blob服务，只实现驱动用到的接口
+// Endpoint 指向测试服务，请求路径为 /container/key
+type fakeBlob struct {
+	mu      sync.Mutex
+	objects drivertest.Objects
+	blocks  map[string][]byte // 已暂存未提交的分块，key 为 blob名称/分块ID
+	commits int               // 提交块列表的次数
+}
+
+func newFakeBlob() *fakeBlob {
+	return &fakeBlob{
+		objects: make(drivertest.Objects),
+		blocks:  make(map[string][]byte),
+	}
+}
+
+func (f *fakeBlob) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// SAS通过查询参数携带签名，其余请求通过 SharedKey 认证
+	q := r.URL.Query()
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey "+testAccount+":") && q.Get("sig") == "" {
+		writeError(w, http.StatusForbidden, "AuthenticationFailed")
+		return
+	}
+
+	containerName, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if containerName != testContainer {
+		writeError(w, http.StatusNotFound, "ContainerNotFound")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && q.Get("restype") == "container" && q.Get("comp") == "list":
+		f.list(w, q)
+	case r.Method == http.MethodPut && q.Get("comp") == "block":
+		data, _ := io.ReadAll(r.Body)
+		f.blocks[key+"/"+q.Get("blockid")] = data
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && q.Get("comp") == "blocklist":
+		var blockList struct {
+			Latest []string
+		}
+		xml.NewDecoder(r.Body).Decode(&blockList)
+		var data []byte
+		for _, id := range blockList.Latest {
+			block, ok := f.blocks[key+"/"+id]
+			if !ok {
+				writeError(w, http.StatusBadRequest, "InvalidBlockList")
+				return
+			}
+			data = append(data, block...)
+		}
+		f.objects[key] = drivertest.NewObject(data, r.Header.Get("x-ms-blob-content-type"))
+		f.commits++
+		w.Header().Set("ETag", drivertest.Object{Data: data}.ETag())
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && r.Header.Get("x-ms-copy-source") != "":
+		source, _ := url.Parse(r.Header.Get("x-ms-copy-source"))
+		_, srcKey, _ := strings.Cut(strings.TrimPrefix(source.Path, "/"), "/")
+		obj, ok := f.objects[srcKey]
+		if !ok {
+			writeError(w, http.StatusNotFound, "CannotVerifyCopySource")
+			return
+		}
+		if _, exists := f.objects[key]; exists && r.Header.Get("If-None-Match") == "*" {
+			writeError(w, http.StatusConflict, "BlobAlreadyExists")
+			return
+		}
+		obj.ModTime = time.Now()
+		f.objects[key] = obj
+		w.Header().Set("x-ms-copy-id", "1")
+		w.Header().Set("x-ms-copy-status", "success")
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPut:
+		if r.Header.Get("x-ms-blob-type") != "BlockBlob" {
+			writeError(w, http.StatusBadRequest, "InvalidHeaderValue")
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key] = drivertest.NewObject(data, r.Header.Get("x-ms-blob-content-type"))
+		w.Header().Set("ETag", drivertest.Object{Data: data}.ETag())
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		obj, ok := f.objects[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "BlobNotFound")
+			return
+		}
+		if rangeHeader := r.Header.Get("x-ms-range"); rangeHeader != "" {
+			r.Header.Set("Range", rangeHeader)
+		}
+		w.Header().Set("x-ms-blob-type", "BlockBlob")
+		obj.Serve(w, r)
+	case r.Method == http.MethodDelete:
+		if _, ok := f.objects[key]; !ok {
+			writeError(w, http.StatusNotFound, "BlobNotFound")
+			return
+		}
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		writeError(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+// list 实现 List Blobs，游标为上一页最后一个Blob名称或前缀
+func (f *fakeBlob) list(w http.ResponseWriter, q url.Values) {
+	maxResults, err := strconv.Atoi(q.Get("maxresults"))
+	if err != nil {
+		maxResults = 5000
+	}
+	keys, prefixes, next := f.objects.List(q.Get("prefix"), q.Get("delimiter"), q.Get("marker"), maxResults)
+
+	type properties struct {
+		LastModified  string `xml:"Last-Modified"`
+		Etag          string
+		ContentLength int    `xml:"Content-Length"`
+		ContentType   string `xml:"Content-Type"`
+	}
+	type blobItem struct {
+		Name       string
+		Properties properties
+	}
+	type blobPrefix struct{ Name string }
+	result := struct {
+		XMLName    xml.Name     `xml:"EnumerationResults"`
+		Prefixes   []blobPrefix `xml:"Blobs>BlobPrefix"`
+		Blobs      []blobItem   `xml:"Blobs>Blob"`
+		NextMarker string
+	}{NextMarker: next}
+	for _, prefix := range prefixes {
+		result.Prefixes = append(result.Prefixes, blobPrefix{prefix})
+	}
+	for _, key := range keys {
+		obj := f.objects[key]
+		result.Blobs = append(result.Blobs, blobItem{Name: key, Properties: properties{
+			LastModified:  obj.ModTime.UTC().Format(http.TimeFormat),
+			Etag:          obj.ETag(),
+			ContentLength: len(obj.Data),
+			ContentType:   obj.ContentType,
+		}})
+	}
+	drivertest.WriteXML(w, result)
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("x-ms-error-code", code)
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
+
+func setupTestServer(t *testing.T) (*azblob.AzblobFilesystem, *fakeBlob, string) {
+	fake := newFakeBlob()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	fs, err := azblob.NewStorage(testAccount, base64.StdEncoding.EncodeToString([]byte("testkey")), azblob.Container{
+		Name:      testContainer,
+		Endpoint:  server.URL,
+		BlockSize: 1024,
+	})
+	if err != nil {
+		t.Fatalf("NewStorage失败：%v", err)
+	}
+	return fs, fake, server.URL
+}
+
+func TestAzblobFilesystem(t *testing.T) {
+	fs, fake, _ := setupTestServer(t)
+	drivertest.Run(t, fs)
+	ctx := context.Background()
+
+	t.Run("分块上传", func(t *testing.T) {
+		commits := fake.commits
+		data := bytes.Repeat([]byte("0123456789"), 350)
+		if err := fs.PutStream(ctx, "large.bin", bytes.NewReader(data), int64(len(data))); err != nil {
+			t.Fatalf("PutStream失败：%v", err)
+		}
+		if fake.commits != commits+1 {
+			t.Errorf("超过分块大小时应使用分块上传")
+		}
+		if retrieved, _ := fs.Get("large.bin"); !bytes.Equal(retrieved, data) {
+			t.Errorf("分块上传的数据不匹配，长度：%d", len(retrieved))
+		}
+	})
+
+	t.Run("删除不存在的文件", func(t *testing.T) {
+		if err := fs.Delete("missing.txt"); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("期望 ErrNotFound，实际：%v", err)
+		}
+	})
+
+	t.Run("覆盖复制", func(t *testing.T) {
+		fs.Put(ctx, "overwrite/a.txt", []byte("新内容"))
+		fs.Put(ctx, "overwrite/b.txt", []byte("旧内容"))
+		if err := fs.Copy(ctx, "overwrite/a.txt", "overwrite/b.txt", true); err != nil {
+			t.Fatalf("覆盖复制失败：%v", err)
+		}
+		if data, _ := fs.Get("overwrite/b.txt"); string(data) != "新内容" {
+			t.Errorf("覆盖后的内容不匹配：%s", string(data))
+		}
+	})
+
+	t.Run("列举的文件信息", func(t *testing.T) {
+		result, err := fs.List(ctx, "overwrite", types.ListOptions{})
+		if err != nil || len(result.Files) == 0 {
+			t.Fatalf("List失败：%v", err)
+		}
+		if info := result.Files[0]; info.Size == 0 || info.ETag == "" || info.LastModified.IsZero() {
+			t.Errorf("文件信息不正确：%+v", info)
+		}
+	})
+}
+
+func TestAzblobFilesystem_GetUrl(t *testing.T) {
+	fs, _, endpoint := setupTestServer(t)
+	if url := fs.GetUrl("dir/a b.txt"); url != endpoint+"/"+testContainer+"/dir/a%20b.txt" {
+		t.Errorf("URL不正确：%s", url)
+	}
+
+	azure, err := azblob.NewStorage("example", "", azblob.Container{Name: "files", SasToken: "sv=2020-02-10&sig=abc"})
+	if err != nil {
+		t.Fatalf("NewStorage失败：%v", err)
+	}
+	if url := azure.GetUrl("/a.txt"); url != "https://example.blob.core.windows.net/files/a.txt" {
+		t.Errorf("URL不正确：%s", url)
+	}
+}
+
+func TestAzblobFilesystem_GetSignedUrl(t *testing.T) {
+	fs, _, _ := setupTestServer(t)
+	if err := fs.Put(context.Background(), "test.txt", []byte("测试数据")); err != nil {
+		t.Fatalf("Put失败：%v", err)
+	}
+
+	signedUrl, err := fs.GetSignedUrl("test.txt", 600)
+	if err != nil {
+		t.Fatalf("GetSignedUrl失败：%v", err)
+	}
+	u, err := url.Parse(signedUrl)
+	if err != nil {
+		t.Fatalf("无法解析签名URL：%v", err)
+	}
+	expiry, err := time.Parse(time.RFC3339, u.Query().Get("se"))
+	if err != nil {
+		t.Fatalf("无法解析过期时间：%v", err)
+	}
+	if d := time.Until(expiry); d < 590*time.Second || d > 600*time.Second {
+		t.Errorf("过期时间不正确：%s", signedUrl)
+	}
+	if u.Query().Get("sp") != "r" || u.Query().Get("sig") == "" {
+		t.Errorf("签名URL应为只读SAS：%s", signedUrl)
+	}
+
+	resp, err := http.Get(signedUrl)
+	if err != nil {
+		t.Fatalf("请求签名URL失败：%v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "测试数据" {
+		t.Errorf("签名URL获取的数据不匹配：%s", string(body))
+	}
+}
+
+func TestAzblobFilesystem_SasToken(t *testing.T) {
+	server := httptest.NewServer(newFakeBlob())
+	t.Cleanup(server.Close)
+
+	fs, err := azblob.NewStorage(testAccount, "", azblob.Container{
+		Name:     testContainer,
+		Endpoint: server.URL,
+		SasToken: "?sv=2020-02-10&sp=rwdl&sig=abc",
+	})
+	if err != nil {
+		t.Fatalf("NewStorage失败：%v", err)
+	}
+	ctx := context.Background()
+
+	if err := fs.Put(ctx, "a.txt", []byte("令牌访问")); err != nil {
+		t.Fatalf("Put失败：%v", err)
+	}
+	if err := fs.Copy(ctx, "a.txt", "b.txt", false); err != nil {
+		t.Fatalf("Copy失败：%v", err)
+	}
+	if data, err := fs.Get("b.txt"); err != nil || string(data) != "令牌访问" {
+		t.Errorf("Get失败：%s %v", string(data), err)
+	}
+	if url := fs.GetUrl("a.txt"); strings.Contains(url, "sig=") {
+		t.Errorf("URL不应包含SAS令牌：%s", url)
+	}
+	if _, err := fs.GetSignedUrl("a.txt", 600); err == nil {
+		t.Error("没有账号密钥时应该无法生成签名URL")
+	}
+}
@@ -0,0 +1,26 @@
+package azblob
+
+import (
+	filesystem "github.com/yu1ec/go-filesystem"
+	"github.com/yu1ec/go-filesystem/config"
+)
+
+func init() {
+	filesystem.RegisterDriver("azblob", func(cfg any) (filesystem.Filesystem, error) {
+		var c config.AzblobDriverConfig
+		if err := filesystem.DecodeConfig(cfg, &c); err != nil {
+			return nil, err
+		}
+		fs, err := NewStorage(c.AccountName, c.AccountKey, Container{
+			Name:      c.Container,
+			Endpoint:  c.Endpoint,
+			SasToken:  c.SasToken,
+			Domain:    c.Domain,
+			BlockSize: c.BlockSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return fs, nil
+	})
+}
@@ -0,0 +1,389 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tencentyun/cos-go-sdk-v5"
+	"github.com/yu1ec/go-filesystem/internal/objstore"
+	"github.com/yu1ec/go-filesystem/types"
+)
+
+// DefaultPartSize 默认分片大小，COS 要求除最后一片外每片不小于 1MB
+const DefaultPartSize = objstore.DefaultPartSize
+
+type CosFilesystem struct {
+	SecretId  string
+	SecretKey string
+	Bucket    Bucket
+
+	bucketUrl  *url.URL
+	httpClient *http.Client
+	client     *cos.Client
+}
+
+// Bucket 存储桶
+type Bucket struct {
+	Name      string // 存储桶名称 格式为 BucketName-APPID，如 examplebucket-1250000000
+	Region    string // 地域 如 ap-guangzhou
+	BucketUrl string // 存储桶地址 为空时根据 Name 和 Region 生成
+	Domain    string // 访问域名 如CDN域名，为空时使用存储桶地址
+	PartSize  int64  // 分片大小 超过此大小的文件使用分片上传，小于等于0时使用 DefaultPartSize
+}
+
+// NewStorage 创建腾讯云COS存储
+func NewStorage(secretId, secretKey string, bucket Bucket) (*CosFilesystem, error) {
+	var bucketUrl *url.URL
+	var err error
+	if bucket.BucketUrl != "" {
+		bucketUrl, err = url.Parse(strings.TrimRight(bucket.BucketUrl, "/"))
+	} else {
+		bucketUrl, err = cos.NewBucketURL(bucket.Name, bucket.Region, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := &http.Client{
+		Transport: &cos.AuthorizationTransport{SecretID: secretId, SecretKey: secretKey},
+	}
+	return &CosFilesystem{
+		SecretId:   secretId,
+		SecretKey:  secretKey,
+		Bucket:     bucket,
+		bucketUrl:  bucketUrl,
+		httpClient: httpClient,
+		client:     cos.NewClient(&cos.BaseURL{BucketURL: bucketUrl}, httpClient),
+	}, nil
+}
+
+func (fs *CosFilesystem) Put(ctx context.Context, path string, data []byte) error {
+	return fs.PutStream(ctx, path, bytes.NewReader(data), int64(len(data)))
+}
+
+func (fs *CosFilesystem) PutWithoutContext(path string, data []byte) error {
+	return fs.Put(context.Background(), path, data)
+}
+
+// PutStream 以流的方式写入文件
+// 数据不超过分片大小时直接上传，否则使用分片上传，内存占用不超过一个分片
+func (fs *CosFilesystem) PutStream(ctx context.Context, path string, reader io.Reader, size int64) error {
+	return convertError("put", path, objstore.PutStream(ctx, uploader{fs}, objstore.Key(path), reader, size, fs.Bucket.PartSize))
+}
+
+// uploader 实现 objstore.Uploader
+type uploader struct {
+	fs *CosFilesystem
+}
+
+func (u uploader) PutObject(ctx context.Context, key, contentType string, data []byte) error {
+	_, err := u.fs.client.Object.Put(ctx, key, bytes.NewReader(data), &cos.ObjectPutOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{ContentType: contentType, ContentLength: int64(len(data))},
+	})
+	return err
+}
+
+func (u uploader) CreateMultipart(ctx context.Context, key, contentType string) (objstore.Multipart, error) {
+	upload, _, err := u.fs.client.Object.InitiateMultipartUpload(ctx, key, &cos.InitiateMultipartUploadOptions{
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{ContentType: contentType},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &multipart{client: u.fs.client, key: key, uploadId: upload.UploadID}, nil
+}
+
+// multipart 进行中的分片上传
+type multipart struct {
+	client   *cos.Client
+	key      string
+	uploadId string
+	parts    []cos.Object
+}
+
+func (m *multipart) UploadPart(ctx context.Context, partNumber int, data []byte) error {
+	resp, err := m.client.Object.UploadPart(ctx, m.key, m.uploadId, partNumber, bytes.NewReader(data), &cos.ObjectUploadPartOptions{
+		ContentLength: int64(len(data)),
+	})
+	if err != nil {
+		return err
+	}
+	m.parts = append(m.parts, cos.Object{PartNumber: partNumber, ETag: resp.Header.Get("ETag")})
+	return nil
+}
+
+func (m *multipart) Complete(ctx context.Context) error {
+	_, _, err := m.client.Object.CompleteMultipartUpload(ctx, m.key, m.uploadId, &cos.CompleteMultipartUploadOptions{Parts: m.parts})
+	return err
+}
+
+func (m *multipart) Abort(ctx context.Context) error {
+	_, err := m.client.Object.AbortMultipartUpload(ctx, m.key, m.uploadId)
+	return err
+}
+
+func (fs *CosFilesystem) Get(path string) ([]byte, error) {
+	return fs.GetWithContext(context.Background(), path)
+}
+
+// GetWithContext 获取文件内容
+func (fs *CosFilesystem) GetWithContext(ctx context.Context, path string) ([]byte, error) {
+	body, err := fs.GetStream(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, types.NewPathError("get", path, nil, err)
+	}
+	return data, nil
+}
+
+// GetStream 以流的方式读取文件
+func (fs *CosFilesystem) GetStream(ctx context.Context, path string) (io.ReadCloser, error) {
+	resp, err := fs.client.Object.Get(ctx, objstore.Key(path), nil)
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+	return resp.Body, nil
+}
+
+// GetStreamRange 从 offset 开始读取 length 字节，length 小于等于0时读取到文件末尾
+func (fs *CosFilesystem) GetStreamRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	resp, err := fs.client.Object.Get(ctx, objstore.Key(path), &cos.ObjectGetOptions{Range: objstore.RangeHeader(offset, length)})
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+	return resp.Body, nil
+}
+
+// GetUrl 获取文件的URL
+// 未设置 Domain 时使用存储桶地址
+func (fs *CosFilesystem) GetUrl(path string) string {
+	key := objstore.EscapeKey(objstore.Key(path))
+	if fs.Bucket.Domain != "" {
+		return strings.TrimRight(fs.Bucket.Domain, "/") + "/" + key
+	}
+	return fs.bucketUrl.String() + "/" + key
+}
+
+// GetSignedUrl 获取签名URL
+// path: 文件路径
+// expires: 过期时间 单位/秒
+func (fs *CosFilesystem) GetSignedUrl(path string, expires int64) (string, error) {
+	u, err := fs.client.Object.GetPresignedURL(context.Background(), http.MethodGet, objstore.Key(path),
+		fs.SecretId, fs.SecretKey, time.Duration(expires)*time.Second, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// MustGetSignedUrl 获取签名URL
+func (fs *CosFilesystem) MustGetSignedUrl(path string, expires int64) string {
+	url, err := fs.GetSignedUrl(path, expires)
+	if err != nil {
+		panic(err)
+	}
+	return url
+}
+
+func (fs *CosFilesystem) GetImageWidthHeight(path string) (int, int, error) {
+	return fs.GetImageWidthHeightWithContext(context.Background(), path)
+}
+
+// GetImageWidthHeightWithContext 获取图片的宽高
+// 使用数据万象的 imageInfo，不需要下载图片
+// SDK 不支持无值的查询参数，因此直接构造请求，签名由 AuthorizationTransport 完成
+func (fs *CosFilesystem) GetImageWidthHeightWithContext(ctx context.Context, path string) (int, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fs.bucketUrl.String()+"/"+objstore.EscapeKey(objstore.Key(path))+"?imageInfo", nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	resp, err := fs.httpClient.Do(req)
+	if err != nil {
+		return 0, 0, convertError("get", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		errResp := &cos.ErrorResponse{Response: resp}
+		xml.NewDecoder(resp.Body).Decode(errResp)
+		return 0, 0, convertError("get", path, errResp)
+	}
+
+	var info struct {
+		Width  string `json:"width"`
+		Height string `json:"height"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return 0, 0, fmt.Errorf("failed to decode image info: %w", err)
+	}
+
+	width, err := strconv.Atoi(info.Width)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid image width: %w", err)
+	}
+	height, err := strconv.Atoi(info.Height)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid image height: %w", err)
+	}
+	return width, height, nil
+}
+
+// Stat 获取文件信息 通过HEAD请求获取
+func (fs *CosFilesystem) Stat(ctx context.Context, path string) (types.FileInfo, error) {
+	resp, err := fs.client.Object.Head(ctx, objstore.Key(path), nil)
+	if err != nil {
+		return types.FileInfo{}, convertError("stat", path, err)
+	}
+
+	lastModified, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return types.FileInfo{
+		Path:         path,
+		Size:         resp.ContentLength,
+		LastModified: lastModified,
+		ContentType:  resp.Header.Get("Content-Type"),
+		ETag:         resp.Header.Get("ETag"),
+	}, nil
+}
+
+// List 列举目录下的文件
+// 游标为COS返回的 NextMarker
+func (fs *CosFilesystem) List(ctx context.Context, prefix string, opts types.ListOptions) (types.ListResult, error) {
+	prefix = objstore.Key(prefix)
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	options := &cos.BucketGetOptions{Prefix: prefix, Marker: opts.Cursor}
+	if !opts.Recursive {
+		options.Delimiter = "/"
+	}
+	if opts.Limit > 0 && opts.Limit < 1000 {
+		options.MaxKeys = opts.Limit
+	}
+
+	ret, _, err := fs.client.Bucket.Get(ctx, options)
+	if err != nil {
+		return types.ListResult{}, convertError("list", prefix, err)
+	}
+
+	result := types.ListResult{}
+	for _, dir := range ret.CommonPrefixes {
+		result.Files = append(result.Files, types.FileInfo{Path: dir, IsDir: true})
+	}
+	for _, item := range ret.Contents {
+		lastModified, _ := time.Parse(time.RFC3339, item.LastModified)
+		result.Files = append(result.Files, types.FileInfo{
+			Path:         item.Key,
+			Size:         item.Size,
+			LastModified: lastModified,
+			ETag:         item.ETag,
+		})
+	}
+	if ret.IsTruncated {
+		result.NextCursor = ret.NextMarker
+	}
+	return result, nil
+}
+
+// Copy 复制文件 使用COS的服务端复制
+func (fs *CosFilesystem) Copy(ctx context.Context, src, dst string, overwrite bool) error {
+	return fs.copy(ctx, "copy", src, dst, overwrite)
+}
+
+// Move 移动文件 COS不支持移动，使用服务端复制后删除源文件
+func (fs *CosFilesystem) Move(ctx context.Context, src, dst string, overwrite bool) error {
+	if err := fs.copy(ctx, "move", src, dst, overwrite); err != nil {
+		return err
+	}
+	_, err := fs.client.Object.Delete(ctx, objstore.Key(src))
+	return convertError("move", src, err)
+}
+
+// copy 不覆盖时先判断目标文件是否存在
+func (fs *CosFilesystem) copy(ctx context.Context, op, src, dst string, overwrite bool) error {
+	if !overwrite {
+		exists, err := fs.ExistsE(ctx, dst)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return types.NewPathError(op, dst, types.ErrAlreadyExists, nil)
+		}
+	}
+
+	_, _, err := fs.client.Object.Copy(ctx, objstore.Key(dst), fs.bucketUrl.Host+"/"+objstore.Key(src), nil)
+	return convertError(op, src, err)
+}
+
+// Delete 删除文件
+func (fs *CosFilesystem) Delete(path string) error {
+	return fs.DeleteWithContext(context.Background(), path)
+}
+
+// DeleteWithContext 删除文件
+// COS删除不存在的文件不会返回错误，因此先通过 HEAD 确认文件存在
+func (fs *CosFilesystem) DeleteWithContext(ctx context.Context, path string) error {
+	if _, err := fs.client.Object.Head(ctx, objstore.Key(path), nil); err != nil {
+		return convertError("delete", path, err)
+	}
+	_, err := fs.client.Object.Delete(ctx, objstore.Key(path))
+	return convertError("delete", path, err)
+}
+
+// Exists 判断文件是否存在
+func (fs *CosFilesystem) Exists(path string) bool {
+	return fs.ExistsWithContext(context.Background(), path)
+}
+
+// ExistsWithContext 判断文件是否存在 无法判断时返回 false，需要区分时使用 ExistsE
+func (fs *CosFilesystem) ExistsWithContext(ctx context.Context, path string) bool {
+	exists, _ := fs.ExistsE(ctx, path)
+	return exists
+}
+
+// ExistsE 判断文件是否存在
+// 文件不存在时返回 false 和 nil，认证失败、网络错误等无法判断的情况返回错误
+func (fs *CosFilesystem) ExistsE(ctx context.Context, path string) (bool, error) {
+	exists, err := fs.client.Object.IsExist(ctx, objstore.Key(path))
+	if err != nil {
+		return false, convertError("exists", path, err)
+	}
+	return exists, nil
+}
+
+// convertError 将COS返回的错误转换为通用错误
+func convertError(op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var errResp *cos.ErrorResponse
+	if errors.As(err, &errResp) {
+		status := 0
+		if errResp.Response != nil {
+			status = errResp.Response.StatusCode
+		}
+		switch {
+		case errResp.Code == "NoSuchKey" || status == http.StatusNotFound:
+			return types.NewPathError(op, path, types.ErrNotFound, err)
+		case status == http.StatusUnauthorized || status == http.StatusForbidden:
+			return types.NewPathError(op, path, types.ErrPermission, err)
+		}
+	}
+	return types.NewPathError(op, path, nil, err)
+}
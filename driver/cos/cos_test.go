@@ -0,0 +1,299 @@
+package cos_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash/crc64"
+	"image"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yu1ec/go-filesystem/driver/cos"
+	"github.com/yu1ec/go-filesystem/internal/drivertest"
+	"github.com/yu1ec/go-filesystem/types"
+)
+
+// fakeCos 简单的COS服务，只实现驱动用到的接口
+// BucketUrl 指向测试服务，请求路径为 /key
+type fakeCos struct {
+	mu         sync.Mutex
+	objects    drivertest.Objects
+	uploads    map[string]map[int][]byte
+	multiparts int // 完成的分片上传次数
+}
+
+func newFakeCos() *fakeCos {
+	return &fakeCos{
+		objects: make(drivertest.Objects),
+		uploads: make(map[string]map[int][]byte),
+	}
+}
+
+func (f *fakeCos) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// 签名URL通过查询参数携带签名，其余请求通过 Authorization 头
+	if r.Header.Get("Authorization") == "" && r.URL.Query().Get("q-signature") == "" {
+		drivertest.WriteXMLError(w, http.StatusForbidden, "AccessDenied")
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	q := r.URL.Query()
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		f.list(w, q)
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		id := strconv.Itoa(len(f.uploads) + 1)
+		f.uploads[id] = make(map[int][]byte)
+		drivertest.WriteXML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Key: key, UploadId: id})
+	case r.Method == http.MethodPut && q.Has("uploadId"):
+		parts, ok := f.uploads[q.Get("uploadId")]
+		if !ok {
+			drivertest.WriteXMLError(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		partNumber, _ := strconv.Atoi(q.Get("partNumber"))
+		parts[partNumber] = data
+		setChecksum(w, data)
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		parts, ok := f.uploads[q.Get("uploadId")]
+		if !ok {
+			drivertest.WriteXMLError(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		var complete struct {
+			Parts []struct {
+				PartNumber int
+				ETag       string
+			} `xml:"Part"`
+		}
+		xml.NewDecoder(r.Body).Decode(&complete)
+		var data []byte
+		for _, part := range complete.Parts {
+			if part.ETag != (drivertest.Object{Data: parts[part.PartNumber]}).ETag() {
+				drivertest.WriteXMLError(w, http.StatusBadRequest, "InvalidPart")
+				return
+			}
+			data = append(data, parts[part.PartNumber]...)
+		}
+		delete(f.uploads, q.Get("uploadId"))
+		f.objects[key] = drivertest.NewObject(data, r.Header.Get("Content-Type"))
+		f.multiparts++
+		drivertest.WriteXML(w, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Key     string
+			ETag    string
+		}{Key: key, ETag: drivertest.Object{Data: data}.ETag()})
+	case r.Method == http.MethodDelete && q.Has("uploadId"):
+		delete(f.uploads, q.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && r.Header.Get("X-Cos-Copy-Source") != "":
+		_, source, _ := strings.Cut(r.Header.Get("X-Cos-Copy-Source"), "/")
+		srcKey, _ := url.PathUnescape(source)
+		obj, ok := f.objects[srcKey]
+		if !ok {
+			drivertest.WriteXMLError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		obj.ModTime = time.Now()
+		f.objects[key] = obj
+		drivertest.WriteXML(w, struct {
+			XMLName      xml.Name `xml:"CopyObjectResult"`
+			LastModified string
+			ETag         string
+		}{LastModified: obj.ModTime.UTC().Format(time.RFC3339), ETag: obj.ETag()})
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key] = drivertest.NewObject(data, r.Header.Get("Content-Type"))
+		setChecksum(w, data)
+	case r.Method == http.MethodGet && q.Has("imageInfo"):
+		obj, ok := f.objects[key]
+		if !ok {
+			drivertest.WriteXMLError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(obj.Data))
+		if err != nil {
+			drivertest.WriteXMLError(w, http.StatusBadRequest, "InvalidImageFormat")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"format":"%s","width":"%d","height":"%d","size":"%d"}`, format, cfg.Width, cfg.Height, len(obj.Data))
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		obj, ok := f.objects[key]
+		if !ok {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			drivertest.WriteXMLError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		obj.Serve(w, r)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		drivertest.WriteXMLError(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+// list 实现 GET Bucket，游标为上一页最后一个key或公共前缀
+func (f *fakeCos) list(w http.ResponseWriter, q url.Values) {
+	maxKeys, err := strconv.Atoi(q.Get("max-keys"))
+	if err != nil {
+		maxKeys = 1000
+	}
+	keys, prefixes, next := f.objects.List(q.Get("prefix"), q.Get("delimiter"), q.Get("marker"), maxKeys)
+
+	type content struct {
+		Key          string
+		LastModified string
+		ETag         string
+		Size         int
+	}
+	result := struct {
+		XMLName        xml.Name `xml:"ListBucketResult"`
+		Contents       []content
+		CommonPrefixes []string `xml:"CommonPrefixes>Prefix"`
+		IsTruncated    bool
+		NextMarker     string `xml:",omitempty"`
+	}{CommonPrefixes: prefixes, IsTruncated: next != "", NextMarker: next}
+	for _, key := range keys {
+		obj := f.objects[key]
+		result.Contents = append(result.Contents, content{
+			Key:          key,
+			LastModified: obj.ModTime.UTC().Format(time.RFC3339),
+			ETag:         obj.ETag(),
+			Size:         len(obj.Data),
+		})
+	}
+	drivertest.WriteXML(w, result)
+}
+
+// setChecksum 与COS一致返回 ETag 和 CRC64，SDK 上传后会校验 CRC64
+func setChecksum(w http.ResponseWriter, data []byte) {
+	w.Header().Set("ETag", drivertest.Object{Data: data}.ETag())
+	w.Header().Set("x-cos-hash-crc64ecma", strconv.FormatUint(crc64.Checksum(data, crc64.MakeTable(crc64.ECMA)), 10))
+}
+
+func setupTestServer(t *testing.T) (*cos.CosFilesystem, *fakeCos) {
+	fake := newFakeCos()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	fs, err := cos.NewStorage("testid", "testkey", cos.Bucket{
+		Name:      "test-1250000000",
+		BucketUrl: server.URL,
+		PartSize:  1024,
+	})
+	if err != nil {
+		t.Fatalf("NewStorage失败：%v", err)
+	}
+	return fs, fake
+}
+
+func TestCosFilesystem(t *testing.T) {
+	fs, fake := setupTestServer(t)
+	drivertest.Run(t, fs)
+	ctx := context.Background()
+
+	t.Run("分片上传", func(t *testing.T) {
+		multiparts := fake.multiparts
+		data := bytes.Repeat([]byte("0123456789"), 350)
+		if err := fs.PutStream(ctx, "large.bin", bytes.NewReader(data), int64(len(data))); err != nil {
+			t.Fatalf("PutStream失败：%v", err)
+		}
+		if fake.multiparts != multiparts+1 {
+			t.Errorf("超过分片大小时应使用分片上传")
+		}
+		if retrieved, _ := fs.Get("large.bin"); !bytes.Equal(retrieved, data) {
+			t.Errorf("分片上传的数据不匹配，长度：%d", len(retrieved))
+		}
+	})
+
+	t.Run("覆盖复制", func(t *testing.T) {
+		fs.Put(ctx, "overwrite/a.txt", []byte("新内容"))
+		fs.Put(ctx, "overwrite/b.txt", []byte("旧内容"))
+		if err := fs.Copy(ctx, "overwrite/a.txt", "overwrite/b.txt", true); err != nil {
+			t.Fatalf("覆盖复制失败：%v", err)
+		}
+		if data, _ := fs.Get("overwrite/b.txt"); string(data) != "新内容" {
+			t.Errorf("覆盖后的内容不匹配：%s", string(data))
+		}
+	})
+
+	t.Run("图片不存在", func(t *testing.T) {
+		if _, _, err := fs.GetImageWidthHeight("missing.png"); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("期望 ErrNotFound，实际：%v", err)
+		}
+	})
+}
+
+func TestCosFilesystem_GetUrl(t *testing.T) {
+	fs, _ := setupTestServer(t)
+	if url := fs.GetUrl("dir/a b.txt"); !strings.HasSuffix(url, "/dir/a%20b.txt") {
+		t.Errorf("URL不正确：%s", url)
+	}
+
+	tencent, err := cos.NewStorage("id", "key", cos.Bucket{Name: "examplebucket-1250000000", Region: "ap-guangzhou"})
+	if err != nil {
+		t.Fatalf("NewStorage失败：%v", err)
+	}
+	if url := tencent.GetUrl("/a.txt"); url != "https://examplebucket-1250000000.cos.ap-guangzhou.myqcloud.com/a.txt" {
+		t.Errorf("URL不正确：%s", url)
+	}
+	if _, err := cos.NewStorage("id", "key", cos.Bucket{Name: "examplebucket-1250000000"}); err == nil {
+		t.Error("缺少地域时应该返回错误")
+	}
+}
+
+func TestCosFilesystem_GetSignedUrl(t *testing.T) {
+	fs, _ := setupTestServer(t)
+	if err := fs.Put(context.Background(), "test.txt", []byte("测试数据")); err != nil {
+		t.Fatalf("Put失败：%v", err)
+	}
+
+	signedUrl, err := fs.GetSignedUrl("test.txt", 600)
+	if err != nil {
+		t.Fatalf("GetSignedUrl失败：%v", err)
+	}
+	u, err := url.Parse(signedUrl)
+	if err != nil {
+		t.Fatalf("无法解析签名URL：%v", err)
+	}
+	start, end, _ := strings.Cut(u.Query().Get("q-key-time"), ";")
+	startTime, _ := strconv.ParseInt(start, 10, 64)
+	endTime, _ := strconv.ParseInt(end, 10, 64)
+	if endTime-startTime != 600 {
+		t.Errorf("过期时间不正确：%s", signedUrl)
+	}
+
+	resp, err := http.Get(signedUrl)
+	if err != nil {
+		t.Fatalf("请求签名URL失败：%v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "测试数据" {
+		t.Errorf("签名URL获取的数据不匹配：%s", string(body))
+	}
+}
@@ -0,0 +1,27 @@
+package cos
+
+import (
+	filesystem "github.com/yu1ec/go-filesystem"
+	"github.com/yu1ec/go-filesystem/config"
+)
+
+func init() {
+	filesystem.RegisterDriver("cos", func(cfg any) (filesystem.Filesystem, error) {
+		var c config.CosDriverConfig
+		if err := filesystem.DecodeConfig(cfg, &c); err != nil {
+			return nil, err
+		}
+		bucket := Bucket{
+			Name:      c.Bucket,
+			Region:    c.Region,
+			BucketUrl: c.BucketUrl,
+			Domain:    c.Domain,
+			PartSize:  c.PartSize,
+		}
+		fs, err := NewStorage(c.SecretId, c.SecretKey, bucket)
+		if err != nil {
+			return nil, err
+		}
+		return fs, nil
+	})
+}
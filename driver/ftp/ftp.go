@@ -0,0 +1,613 @@
+package ftp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"mime"
+	"net"
+	"net/textproto"
+	"net/url"
+	pathpkg "path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jlaffaye/ftp"
+	"github.com/yu1ec/go-filesystem/internal/fsutil"
+	"github.com/yu1ec/go-filesystem/types"
+)
+
+// TLS模式
+const (
+	TLSNone     = ""         // 不加密
+	TLSExplicit = "explicit" // 显式FTPS 连接后通过 AUTH TLS 升级
+	TLSImplicit = "implicit" // 隐式FTPS 连接时即使用TLS
+)
+
+const (
+	DefaultPoolSize = 2                // 默认保留的空闲连接数
+	DefaultTimeout  = 30 * time.Second // 默认连接超时时间
+
+	// idleCheckAfter 空闲超过此时间的连接在复用前使用 NOOP 检查是否可用
+	idleCheckAfter = 15 * time.Second
+)
+
+type FtpFilesystem struct {
+	Server Server
+
+	options []ftp.DialOption
+
+	mu   sync.Mutex
+	idle []idleConn
+}
+
+// idleConn 连接池中的空闲连接
+type idleConn struct {
+	conn     *ftp.ServerConn
+	lastUsed time.Time
+}
+
+// Server FTP服务器
+type Server struct {
+	Host               string        // 主机
+	Port               int           // 端口 为0时使用21，隐式FTPS使用990
+	Username           string        // 用户名 为空时匿名登录
+	Password           string        // 密码
+	Root               string        // 根目录 为空时使用登录后的默认目录
+	TLS                string        // TLS模式 TLSNone、TLSExplicit 或 TLSImplicit
+	TLSConfig          *tls.Config   // 自定义TLS配置 如CA证书、客户端证书
+	InsecureSkipVerify bool          // 不校验服务器证书 仅用于测试
+	DisableEPSV        bool          // 禁用EPSV只使用PASV 部分服务器在NAT后时需要
+	PoolSize           int           // 保留的空闲连接数 小于等于0时使用 DefaultPoolSize
+	Timeout            time.Duration // 连接超时 小于等于0时使用 DefaultTimeout
+}
+
+// NewStorage 创建FTP存储 创建时会连接服务器以校验认证信息
+// FTP仅支持被动模式，优先使用EPSV
+func NewStorage(server Server) (*FtpFilesystem, error) {
+	options, err := dialOptions(server)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := &FtpFilesystem{Server: server, options: options}
+	conn, err := fs.dial(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	fs.release(conn, nil)
+	return fs, nil
+}
+
+func dialOptions(server Server) ([]ftp.DialOption, error) {
+	timeout := server.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	options := []ftp.DialOption{
+		ftp.DialWithTimeout(timeout),
+		ftp.DialWithDisabledEPSV(server.DisableEPSV),
+	}
+
+	var tlsConfig *tls.Config
+	if server.TLS != TLSNone {
+		if server.TLSConfig != nil {
+			tlsConfig = server.TLSConfig.Clone()
+		} else {
+			tlsConfig = &tls.Config{}
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = server.Host
+		}
+		tlsConfig.InsecureSkipVerify = tlsConfig.InsecureSkipVerify || server.InsecureSkipVerify
+	}
+
+	switch server.TLS {
+	case TLSNone:
+	case TLSExplicit:
+		options = append(options, ftp.DialWithExplicitTLS(tlsConfig))
+	case TLSImplicit:
+		options = append(options, ftp.DialWithTLS(tlsConfig))
+	default:
+		return nil, fmt.Errorf("unsupported tls mode: %s", server.TLS)
+	}
+	return options, nil
+}
+
+// dial 建立新的连接并登录
+func (fs *FtpFilesystem) dial(ctx context.Context) (*ftp.ServerConn, error) {
+	// Clip 避免并发拨号时共用 options 的底层数组
+	conn, err := ftp.Dial(fs.addr(), append(slices.Clip(fs.options), ftp.DialWithContext(ctx))...)
+	if err != nil {
+		return nil, err
+	}
+
+	username, password := fs.Server.Username, fs.Server.Password
+	if username == "" {
+		username, password = "anonymous", "anonymous"
+	}
+	if err := conn.Login(username, password); err != nil {
+		conn.Quit()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// acquire 从连接池获取连接 没有可用的空闲连接时建立新连接
+func (fs *FtpFilesystem) acquire(ctx context.Context) (*ftp.ServerConn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for {
+		fs.mu.Lock()
+		n := len(fs.idle)
+		if n == 0 {
+			fs.mu.Unlock()
+			return fs.dial(ctx)
+		}
+		idle := fs.idle[n-1]
+		fs.idle = fs.idle[:n-1]
+		fs.mu.Unlock()
+
+		// 空闲较久的连接可能已被服务器关闭
+		if time.Since(idle.lastUsed) < idleCheckAfter || idle.conn.NoOp() == nil {
+			return idle.conn, nil
+		}
+		idle.conn.Quit()
+	}
+}
+
+// release 归还连接 连接池已满或发生网络错误时关闭连接
+// 服务器返回的状态码错误不影响连接继续使用
+func (fs *FtpFilesystem) release(conn *ftp.ServerConn, err error) {
+	var protoErr *textproto.Error
+	if err != nil && !errors.As(err, &protoErr) {
+		conn.Quit()
+		return
+	}
+
+	poolSize := fs.Server.PoolSize
+	if poolSize <= 0 {
+		poolSize = DefaultPoolSize
+	}
+
+	fs.mu.Lock()
+	if len(fs.idle) < poolSize {
+		fs.idle = append(fs.idle, idleConn{conn: conn, lastUsed: time.Now()})
+		conn = nil
+	}
+	fs.mu.Unlock()
+
+	if conn != nil {
+		conn.Quit()
+	}
+}
+
+// do 获取连接执行操作后归还
+func (fs *FtpFilesystem) do(ctx context.Context, fn func(conn *ftp.ServerConn) error) error {
+	conn, err := fs.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	err = fn(conn)
+	fs.release(conn, err)
+	return err
+}
+
+// Close 关闭连接池中的空闲连接
+func (fs *FtpFilesystem) Close() error {
+	fs.mu.Lock()
+	idle := fs.idle
+	fs.idle = nil
+	fs.mu.Unlock()
+
+	var errs []error
+	for _, c := range idle {
+		errs = append(errs, c.conn.Quit())
+	}
+	return errors.Join(errs...)
+}
+
+func (fs *FtpFilesystem) addr() string {
+	port := fs.Server.Port
+	if port == 0 {
+		port = 21
+		if fs.Server.TLS == TLSImplicit {
+			port = 990
+		}
+	}
+	return net.JoinHostPort(fs.Server.Host, strconv.Itoa(port))
+}
+
+// fullPath 获取文件在服务器上的路径 路径不会超出根目录
+func (fs *FtpFilesystem) fullPath(path string) string {
+	return pathpkg.Join(fs.Server.Root, ".", pathpkg.Clean("/"+path))
+}
+
+func (fs *FtpFilesystem) Put(ctx context.Context, path string, data []byte) error {
+	return fs.PutStream(ctx, path, bytes.NewReader(data), int64(len(data)))
+}
+
+func (fs *FtpFilesystem) PutWithoutContext(path string, data []byte) error {
+	return fs.Put(context.Background(), path, data)
+}
+
+// PutStream 以流的方式写入文件 目录不存在时逐级创建
+func (fs *FtpFilesystem) PutStream(ctx context.Context, path string, reader io.Reader, size int64) error {
+	fullPath := fs.fullPath(path)
+	err := fs.do(ctx, func(conn *ftp.ServerConn) error {
+		mkdirAll(conn, pathpkg.Dir(fullPath))
+		return conn.Stor(fullPath, fsutil.ContextReader(ctx, reader))
+	})
+	return convertError("put", path, err)
+}
+
+// mkdirAll 逐级创建目录
+// FTP无法区分目录已存在和其他错误，因此忽略创建失败，由后续操作返回错误
+func mkdirAll(conn *ftp.ServerConn, dir string) {
+	if dir == "." || dir == "/" {
+		return
+	}
+	prefix := ""
+	if strings.HasPrefix(dir, "/") {
+		prefix = "/"
+	}
+	for _, segment := range strings.Split(strings.Trim(dir, "/"), "/") {
+		prefix = pathpkg.Join(prefix, segment)
+		conn.MakeDir(prefix)
+	}
+}
+
+func (fs *FtpFilesystem) Get(path string) ([]byte, error) {
+	return fs.GetWithContext(context.Background(), path)
+}
+
+// GetWithContext 获取文件内容
+func (fs *FtpFilesystem) GetWithContext(ctx context.Context, path string) ([]byte, error) {
+	body, err := fs.GetStream(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(fsutil.ContextReader(ctx, body))
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+	return data, nil
+}
+
+// GetStream 以流的方式读取文件 关闭后连接归还连接池
+func (fs *FtpFilesystem) GetStream(ctx context.Context, path string) (io.ReadCloser, error) {
+	conn, err := fs.acquire(ctx)
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+
+	resp, err := retr(conn, fs.fullPath(path))
+	if err != nil {
+		fs.release(conn, err)
+		return nil, convertError("get", path, err)
+	}
+	return &response{Response: resp, fs: fs, conn: conn}, nil
+}
+
+// retr 下载文件
+// 部分服务器下载不存在的文件时返回551等状态码，此时通过 stat 确认文件是否存在
+func retr(conn *ftp.ServerConn, fullPath string) (*ftp.Response, error) {
+	resp, err := conn.Retr(fullPath)
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code != ftp.StatusFileUnavailable {
+		if _, statErr := stat(conn, fullPath); isStatus(statErr, ftp.StatusFileUnavailable) {
+			return nil, statErr
+		}
+	}
+	return resp, err
+}
+
+// response 数据连接关闭后归还控制连接
+type response struct {
+	*ftp.Response
+	fs     *FtpFilesystem
+	conn   *ftp.ServerConn
+	closed bool
+}
+
+func (r *response) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+
+	err := r.Response.Close()
+	r.fs.release(r.conn, err)
+	return err
+}
+
+// GetUrl 获取文件的URL 格式为 ftp://user@host:port/path，不包含密码
+// 隐式FTPS使用 ftps 协议
+func (fs *FtpFilesystem) GetUrl(path string) string {
+	fullPath := fs.fullPath(path)
+	if !strings.HasPrefix(fullPath, "/") {
+		fullPath = "/" + fullPath
+	}
+
+	u := url.URL{Scheme: "ftp", Host: fs.Server.Host, Path: fullPath}
+	if fs.Server.TLS == TLSImplicit {
+		u.Scheme = "ftps"
+	}
+	if fs.Server.Port != 0 {
+		u.Host = net.JoinHostPort(fs.Server.Host, strconv.Itoa(fs.Server.Port))
+	}
+	if fs.Server.Username != "" {
+		u.User = url.User(fs.Server.Username)
+	}
+	return u.String()
+}
+
+// GetSignedUrl FTP不支持签名URL，返回与 GetUrl 相同的URL
+func (fs *FtpFilesystem) GetSignedUrl(path string, expires int64) (string, error) {
+	return fs.GetUrl(path), nil
+}
+
+// MustGetSignedUrl 获取签名URL
+func (fs *FtpFilesystem) MustGetSignedUrl(path string, expires int64) string {
+	url, err := fs.GetSignedUrl(path, expires)
+	if err != nil {
+		panic(err)
+	}
+	return url
+}
+
+func (fs *FtpFilesystem) GetImageWidthHeight(path string) (int, int, error) {
+	return fs.GetImageWidthHeightWithContext(context.Background(), path)
+}
+
+// GetImageWidthHeightWithContext 获取图片的宽高 只读取图片头部
+func (fs *FtpFilesystem) GetImageWidthHeightWithContext(ctx context.Context, path string) (int, int, error) {
+	body, err := fs.GetStream(ctx, path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer body.Close()
+
+	cfg, _, err := image.DecodeConfig(fsutil.ContextReader(ctx, body))
+	if err != nil {
+		return 0, 0, err
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// Stat 获取文件信息
+// 服务器支持 MLST 时使用 MLST，否则使用 SIZE 和 MDTM
+// ETag 由修改时间和文件大小生成，MIME类型仅根据扩展名判断
+func (fs *FtpFilesystem) Stat(ctx context.Context, path string) (types.FileInfo, error) {
+	var entry *ftp.Entry
+	err := fs.do(ctx, func(conn *ftp.ServerConn) error {
+		var err error
+		entry, err = stat(conn, fs.fullPath(path))
+		return err
+	})
+	if err != nil {
+		return types.FileInfo{}, convertError("stat", path, err)
+	}
+	return toFileInfo(path, entry), nil
+}
+
+func stat(conn *ftp.ServerConn, fullPath string) (*ftp.Entry, error) {
+	entry, err := conn.GetEntry(fullPath)
+	if !isStatus(err, ftp.StatusNotImplemented) {
+		return entry, err
+	}
+
+	size, err := conn.FileSize(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	entry = &ftp.Entry{Name: pathpkg.Base(fullPath), Type: ftp.EntryTypeFile, Size: uint64(size)}
+	if conn.IsGetTimeSupported() {
+		entry.Time, _ = conn.GetTime(fullPath)
+	}
+	return entry, nil
+}
+
+// List 列举目录下的文件
+// prefix 为目录路径，目录不存在时返回空列表
+func (fs *FtpFilesystem) List(ctx context.Context, prefix string, opts types.ListOptions) (types.ListResult, error) {
+	var files []types.FileInfo
+	err := fs.do(ctx, func(conn *ftp.ServerConn) error {
+		return fs.readDir(ctx, conn, prefix, opts.Recursive, &files)
+	})
+	if err != nil && !isStatus(err, ftp.StatusFileUnavailable) {
+		return types.ListResult{}, convertError("list", prefix, err)
+	}
+	return types.Paginate(files, opts.Cursor, opts.Limit), nil
+}
+
+// readDir 读取目录，递归时只收集文件
+func (fs *FtpFilesystem) readDir(ctx context.Context, conn *ftp.ServerConn, dir string, recursive bool, files *[]types.FileInfo) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	entries, err := conn.List(fs.fullPath(dir))
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.Name == "." || entry.Name == ".." {
+			continue
+		}
+		filePath := pathpkg.Join(dir, entry.Name)
+		if entry.Type == ftp.EntryTypeFolder && recursive {
+			if err := fs.readDir(ctx, conn, filePath, recursive, files); err != nil {
+				return err
+			}
+			continue
+		}
+		*files = append(*files, toFileInfo(filePath, entry))
+	}
+	return nil
+}
+
+// toFileInfo 将FTP的文件信息转换为通用的文件信息
+// 目录路径以 / 结尾
+func toFileInfo(path string, entry *ftp.Entry) types.FileInfo {
+	if entry.Type == ftp.EntryTypeFolder {
+		return types.FileInfo{
+			Path:         strings.TrimSuffix(path, "/") + "/",
+			LastModified: entry.Time,
+			IsDir:        true,
+		}
+	}
+
+	return types.FileInfo{
+		Path:         path,
+		Size:         int64(entry.Size),
+		LastModified: entry.Time,
+		ContentType:  mime.TypeByExtension(pathpkg.Ext(path)),
+		ETag:         fsutil.ETag(entry.Time, int64(entry.Size)),
+	}
+}
+
+// Copy 复制文件 FTP不支持服务端复制，通过读取源文件后写入目标文件实现
+// 读写分别使用连接池中的两个连接
+func (fs *FtpFilesystem) Copy(ctx context.Context, src, dst string, overwrite bool) error {
+	if !overwrite {
+		exists, err := fs.ExistsE(ctx, dst)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return types.NewPathError("copy", dst, types.ErrAlreadyExists, nil)
+		}
+	}
+	if fs.fullPath(src) == fs.fullPath(dst) {
+		return nil
+	}
+
+	conn, err := fs.acquire(ctx)
+	if err != nil {
+		return convertError("copy", src, err)
+	}
+	resp, err := retr(conn, fs.fullPath(src))
+	if err != nil {
+		fs.release(conn, err)
+		return convertError("copy", src, err)
+	}
+	body := &response{Response: resp, fs: fs, conn: conn}
+	defer body.Close()
+
+	return fs.PutStream(ctx, dst, body, -1)
+}
+
+// Move 移动文件 使用 RNFR/RNTO 重命名
+// 覆盖时部分服务器不允许重命名到已存在的文件，此时确认源文件和目标文件都存在后删除目标文件再重命名
+func (fs *FtpFilesystem) Move(ctx context.Context, src, dst string, overwrite bool) error {
+	if !overwrite {
+		exists, err := fs.ExistsE(ctx, dst)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return types.NewPathError("move", dst, types.ErrAlreadyExists, nil)
+		}
+	}
+
+	srcPath, dstPath := fs.fullPath(src), fs.fullPath(dst)
+	err := fs.do(ctx, func(conn *ftp.ServerConn) error {
+		// 源文件不存在时不能删除目标文件
+		if _, err := stat(conn, srcPath); err != nil {
+			return err
+		}
+		mkdirAll(conn, pathpkg.Dir(dstPath))
+		err := conn.Rename(srcPath, dstPath)
+		if err == nil || !overwrite {
+			return err
+		}
+		if _, statErr := stat(conn, dstPath); statErr != nil {
+			return err
+		}
+		if err := conn.Delete(dstPath); err != nil {
+			return err
+		}
+		return conn.Rename(srcPath, dstPath)
+	})
+	return convertError("move", src, err)
+}
+
+// Delete 删除文件
+func (fs *FtpFilesystem) Delete(path string) error {
+	return fs.DeleteWithContext(context.Background(), path)
+}
+
+// DeleteWithContext 删除文件
+func (fs *FtpFilesystem) DeleteWithContext(ctx context.Context, path string) error {
+	err := fs.do(ctx, func(conn *ftp.ServerConn) error {
+		return conn.Delete(fs.fullPath(path))
+	})
+	return convertError("delete", path, err)
+}
+
+// Exists 判断文件是否存在
+func (fs *FtpFilesystem) Exists(path string) bool {
+	return fs.ExistsWithContext(context.Background(), path)
+}
+
+// ExistsWithContext 判断文件是否存在 无法判断时返回 false，需要区分时使用 ExistsE
+func (fs *FtpFilesystem) ExistsWithContext(ctx context.Context, path string) bool {
+	exists, _ := fs.ExistsE(ctx, path)
+	return exists
+}
+
+// ExistsE 判断文件是否存在
+// 服务器返回550时返回 false 和 nil，网络错误、认证失败等无法判断的情况返回错误
+func (fs *FtpFilesystem) ExistsE(ctx context.Context, path string) (bool, error) {
+	err := fs.do(ctx, func(conn *ftp.ServerConn) error {
+		_, err := stat(conn, fs.fullPath(path))
+		return err
+	})
+	if err == nil {
+		return true, nil
+	}
+	if isStatus(err, ftp.StatusFileUnavailable) {
+		return false, nil
+	}
+	return false, convertError("exists", path, err)
+}
+
+// isStatus 判断是否为服务器返回的指定状态码
+func isStatus(err error, code int) bool {
+	var protoErr *textproto.Error
+	return errors.As(err, &protoErr) && protoErr.Code == code
+}
+
+// convertError 将FTP状态码转换为通用错误
+// 550 表示文件不可用，大多数服务器在文件不存在时返回
+func convertError(op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		switch protoErr.Code {
+		case ftp.StatusFileUnavailable:
+			return types.NewPathError(op, path, types.ErrNotFound, err)
+		case ftp.StatusNotLoggedIn:
+			return types.NewPathError(op, path, types.ErrPermission, err)
+		case ftp.StatusBadFileName:
+			return types.NewPathError(op, path, types.ErrInvalidPath, err)
+		}
+	}
+	return types.NewPathError(op, path, nil, err)
+}
@@ -0,0 +1,213 @@
+package ftp_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ftpfs "github.com/yu1ec/go-filesystem/driver/ftp"
+	"github.com/yu1ec/go-filesystem/internal/drivertest"
+	"github.com/yu1ec/go-filesystem/types"
+	"goftp.io/server/v2"
+	"goftp.io/server/v2/driver/file"
+)
+
+const (
+	testUser     = "tester"
+	testPassword = "secret"
+)
+
+// startServer 启动进程内的FTP服务器 tlsConfig 不为空时启用显式FTPS
+func startServer(t *testing.T, tlsConfig *tls.Config) (int, string) {
+	root := t.TempDir()
+	driver, err := file.NewDriver(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// goftp 只有 ListenAndServe 会加载TLS配置，因此先获取一个空闲端口
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	s, err := server.NewServer(&server.Options{
+		Driver:       driver,
+		Auth:         &server.SimpleAuth{Name: testUser, Password: testPassword},
+		Perm:         server.NewSimplePerm("test", "test"),
+		Hostname:     "127.0.0.1",
+		Port:         port,
+		Logger:       &server.DiscardLogger{},
+		TLS:          tlsConfig != nil,
+		ExplicitFTPS: true,
+		TLSConfig:    tlsConfig,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.ListenAndServe()
+	t.Cleanup(func() { s.Shutdown() })
+
+	for i := 0; i < 100; i++ {
+		if conn, err := net.Dial("tcp", l.Addr().String()); err == nil {
+			conn.Close()
+			return port, root
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("FTP服务器启动超时")
+	return 0, ""
+}
+
+// selfSignedCert 生成 127.0.0.1 的自签名证书
+func selfSignedCert(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+func TestFtpFilesystem(t *testing.T) {
+	port, root := startServer(t, nil)
+	fs, err := ftpfs.NewStorage(ftpfs.Server{
+		Host:     "127.0.0.1",
+		Port:     port,
+		Username: testUser,
+		Password: testPassword,
+		Root:     "/data",
+	})
+	if err != nil {
+		t.Fatalf("NewStorage失败：%v", err)
+	}
+	defer fs.Close()
+	drivertest.Run(t, fs)
+	ctx := context.Background()
+
+	t.Run("写入根目录", func(t *testing.T) {
+		if err := fs.Put(ctx, "dir/sub/test.txt", []byte("测试数据")); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+		if _, err := os.Stat(filepath.Join(root, "data/dir/sub/test.txt")); err != nil {
+			t.Fatalf("文件未写入根目录：%v", err)
+		}
+	})
+
+	t.Run("覆盖移动", func(t *testing.T) {
+		if err := fs.Put(ctx, "overwrite/a.txt", []byte("新内容")); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+		if err := fs.Put(ctx, "overwrite/b.txt", []byte("旧内容")); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+
+		// 源文件不存在时目标文件应保留
+		if err := fs.Move(ctx, "overwrite/missing.txt", "overwrite/b.txt", true); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("期望 ErrNotFound，实际：%v", err)
+		}
+		if data, _ := fs.Get("overwrite/b.txt"); string(data) != "旧内容" {
+			t.Fatalf("源文件不存在时目标文件不应被删除：%s", string(data))
+		}
+
+		if err := fs.Move(ctx, "overwrite/a.txt", "overwrite/b.txt", true); err != nil {
+			t.Fatalf("覆盖移动失败：%v", err)
+		}
+		if data, _ := fs.Get("overwrite/b.txt"); string(data) != "新内容" {
+			t.Errorf("移动后的内容不匹配：%s", string(data))
+		}
+	})
+
+	t.Run("GetUrl", func(t *testing.T) {
+		if url := fs.GetUrl("dir/a b.txt"); !strings.HasPrefix(url, "ftp://"+testUser+"@127.0.0.1:") || !strings.HasSuffix(url, "/data/dir/a%20b.txt") {
+			t.Errorf("URL不正确：%s", url)
+		}
+	})
+}
+
+func TestFtpFilesystem_ExplicitTLS(t *testing.T) {
+	cert, pool := selfSignedCert(t)
+	port, _ := startServer(t, &tls.Config{Certificates: []tls.Certificate{cert}})
+
+	t.Run("校验证书", func(t *testing.T) {
+		fs, err := ftpfs.NewStorage(ftpfs.Server{
+			Host:      "127.0.0.1",
+			Port:      port,
+			Username:  testUser,
+			Password:  testPassword,
+			TLS:       ftpfs.TLSExplicit,
+			TLSConfig: &tls.Config{RootCAs: pool},
+		})
+		if err != nil {
+			t.Fatalf("NewStorage失败：%v", err)
+		}
+		defer fs.Close()
+
+		if err := fs.Put(context.Background(), "tls.txt", []byte("加密传输")); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+		if data, err := fs.Get("tls.txt"); err != nil || string(data) != "加密传输" {
+			t.Errorf("Get失败：%s %v", string(data), err)
+		}
+	})
+
+	t.Run("证书不受信任", func(t *testing.T) {
+		_, err := ftpfs.NewStorage(ftpfs.Server{
+			Host:     "127.0.0.1",
+			Port:     port,
+			Username: testUser,
+			Password: testPassword,
+			TLS:      ftpfs.TLSExplicit,
+		})
+		if err == nil {
+			t.Error("证书不受信任时应该返回错误")
+		}
+	})
+}
+
+func TestFtpFilesystem_Auth(t *testing.T) {
+	port, _ := startServer(t, nil)
+	_, err := ftpfs.NewStorage(ftpfs.Server{
+		Host:     "127.0.0.1",
+		Port:     port,
+		Username: testUser,
+		Password: "wrong",
+	})
+	if err == nil {
+		t.Error("密码错误时应该返回错误")
+	}
+}
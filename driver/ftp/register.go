@@ -0,0 +1,30 @@
+package ftp
+
+import (
+	filesystem "github.com/yu1ec/go-filesystem"
+	"github.com/yu1ec/go-filesystem/config"
+)
+
+func init() {
+	filesystem.RegisterDriver("ftp", func(cfg any) (filesystem.Filesystem, error) {
+		var c config.FtpDriverConfig
+		if err := filesystem.DecodeConfig(cfg, &c); err != nil {
+			return nil, err
+		}
+		fs, err := NewStorage(Server{
+			Host:               c.Host,
+			Port:               c.Port,
+			Username:           c.Username,
+			Password:           c.Password,
+			Root:               c.Root,
+			TLS:                c.TLS,
+			InsecureSkipVerify: c.InsecureSkipVerify,
+			DisableEPSV:        c.DisableEPSV,
+			PoolSize:           c.PoolSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return fs, nil
+	})
+}
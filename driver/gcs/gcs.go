@@ -0,0 +1,392 @@
+package gcs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	pathpkg "path"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/yu1ec/go-filesystem/internal/objstore"
+	"github.com/yu1ec/go-filesystem/types"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// DefaultChunkSize 默认分块大小，SDK 会向上取整为 256KB 的倍数
+const DefaultChunkSize = objstore.DefaultPartSize
+
+// defaultListLimit 未指定 Limit 时每页返回的数量
+const defaultListLimit = 1000
+
+type GcsFilesystem struct {
+	Bucket Bucket
+
+	accessId   string // 服务账号邮箱 用于签名URL
+	privateKey []byte // 服务账号私钥 用于签名URL
+	endpoint   *url.URL
+	client     *storage.Client
+}
+
+// Bucket 存储桶
+type Bucket struct {
+	Name      string // 存储桶名称
+	Endpoint  string // 服务地址 为空时使用 https://storage.googleapis.com，可指向模拟器
+	Domain    string // 访问域名 如CDN域名，为空时使用服务地址
+	ChunkSize int    // 分块大小 超过此大小的文件使用断点续传上传，小于等于0时使用 DefaultChunkSize
+}
+
+// serviceAccount 服务账号密钥文件中签名需要的字段
+type serviceAccount struct {
+	Type        string `json:"type"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+}
+
+// NewStorage 创建Google Cloud Storage存储
+// credentials 为服务账号的JSON密钥，为空时使用应用默认凭据(ADC)
+func NewStorage(credentials string, bucket Bucket) (*GcsFilesystem, error) {
+	fs := &GcsFilesystem{Bucket: bucket}
+
+	var opts []option.ClientOption
+	if credentials != "" {
+		var account serviceAccount
+		if err := json.Unmarshal([]byte(credentials), &account); err != nil {
+			return nil, fmt.Errorf("invalid credentials: %w", err)
+		}
+		// 只接受服务账号密钥，避免外部配置引入其他类型的凭据
+		if account.Type != "service_account" {
+			return nil, fmt.Errorf("invalid credentials: unsupported type %q, expected service_account", account.Type)
+		}
+		fs.accessId, fs.privateKey = account.ClientEmail, []byte(account.PrivateKey)
+		opts = append(opts, option.WithCredentialsJSON([]byte(credentials)))
+	}
+	if bucket.Endpoint != "" {
+		endpoint, err := url.Parse(strings.TrimRight(bucket.Endpoint, "/"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint: %w", err)
+		}
+		fs.endpoint = endpoint
+		opts = append(opts, option.WithEndpoint(endpoint.String()+"/storage/v1/"))
+	}
+
+	client, err := storage.NewClient(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	fs.client = client
+	return fs, nil
+}
+
+func (fs *GcsFilesystem) Put(ctx context.Context, path string, data []byte) error {
+	return fs.PutStream(ctx, path, bytes.NewReader(data), int64(len(data)))
+}
+
+func (fs *GcsFilesystem) PutWithoutContext(path string, data []byte) error {
+	return fs.Put(context.Background(), path, data)
+}
+
+// PutStream 以流的方式写入文件
+// 数据不超过分块大小时一次上传，否则使用断点续传上传，内存占用不超过一个分块
+func (fs *GcsFilesystem) PutStream(ctx context.Context, path string, reader io.Reader, size int64) error {
+	key := objstore.Key(path)
+
+	// 取消上下文才能中止上传，否则 Close 会提交已写入的部分数据
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := fs.object(key).NewWriter(ctx)
+	w.ChunkSize = fs.chunkSize(size)
+	// 未知扩展名时由SDK根据内容检测
+	w.ContentType = mime.TypeByExtension(pathpkg.Ext(key))
+
+	if _, err := io.Copy(w, reader); err != nil {
+		cancel()
+		w.Close()
+		return types.NewPathError("put", path, nil, err)
+	}
+	return convertError("put", path, w.Close())
+}
+
+func (fs *GcsFilesystem) Get(path string) ([]byte, error) {
+	return fs.GetWithContext(context.Background(), path)
+}
+
+// GetWithContext 获取文件内容
+func (fs *GcsFilesystem) GetWithContext(ctx context.Context, path string) ([]byte, error) {
+	body, err := fs.GetStream(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, types.NewPathError("get", path, nil, err)
+	}
+	return data, nil
+}
+
+// GetStream 以流的方式读取文件
+func (fs *GcsFilesystem) GetStream(ctx context.Context, path string) (io.ReadCloser, error) {
+	reader, err := fs.object(objstore.Key(path)).NewReader(ctx)
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+	return reader, nil
+}
+
+// GetStreamRange 从 offset 开始读取 length 字节，length 小于等于0时读取到文件末尾
+func (fs *GcsFilesystem) GetStreamRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	if length <= 0 {
+		length = -1
+	}
+	reader, err := fs.object(objstore.Key(path)).NewRangeReader(ctx, offset, length)
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+	return reader, nil
+}
+
+// GetUrl 获取文件的URL
+// 未设置 Domain 时使用服务地址
+func (fs *GcsFilesystem) GetUrl(path string) string {
+	key := objstore.EscapeKey(objstore.Key(path))
+	if fs.Bucket.Domain != "" {
+		return strings.TrimRight(fs.Bucket.Domain, "/") + "/" + key
+	}
+	endpoint := "https://storage.googleapis.com"
+	if fs.endpoint != nil {
+		endpoint = fs.endpoint.String()
+	}
+	return endpoint + "/" + url.PathEscape(fs.Bucket.Name) + "/" + key
+}
+
+// GetSignedUrl 获取V4签名URL
+// path: 文件路径
+// expires: 过期时间 单位/秒，最长7天
+// 未配置服务账号密钥时由SDK从默认凭据获取签名方式
+func (fs *GcsFilesystem) GetSignedUrl(path string, expires int64) (string, error) {
+	opts := &storage.SignedURLOptions{
+		GoogleAccessID: fs.accessId,
+		Method:         http.MethodGet,
+		Expires:        time.Now().Add(time.Duration(expires) * time.Second),
+		Scheme:         storage.SigningSchemeV4,
+	}
+	if len(fs.privateKey) > 0 {
+		opts.PrivateKey = fs.privateKey
+	}
+	if fs.endpoint != nil {
+		opts.Hostname = fs.endpoint.Host
+		opts.Insecure = fs.endpoint.Scheme == "http"
+	}
+
+	u, err := fs.client.Bucket(fs.Bucket.Name).SignedURL(objstore.Key(path), opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign url: %w", err)
+	}
+	return u, nil
+}
+
+// MustGetSignedUrl 获取签名URL
+func (fs *GcsFilesystem) MustGetSignedUrl(path string, expires int64) string {
+	url, err := fs.GetSignedUrl(path, expires)
+	if err != nil {
+		panic(err)
+	}
+	return url
+}
+
+func (fs *GcsFilesystem) GetImageWidthHeight(path string) (int, int, error) {
+	return fs.GetImageWidthHeightWithContext(context.Background(), path)
+}
+
+// GetImageWidthHeightWithContext 获取图片的宽高
+// 只读取解析图片头所需的数据
+func (fs *GcsFilesystem) GetImageWidthHeightWithContext(ctx context.Context, path string) (int, int, error) {
+	body, err := fs.GetStream(ctx, path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer body.Close()
+
+	cfg, _, err := image.DecodeConfig(body)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// Stat 获取文件信息 通过JSON API获取对象元数据
+func (fs *GcsFilesystem) Stat(ctx context.Context, path string) (types.FileInfo, error) {
+	attrs, err := fs.object(objstore.Key(path)).Attrs(ctx)
+	if err != nil {
+		return types.FileInfo{}, convertError("stat", path, err)
+	}
+
+	info := toFileInfo(attrs)
+	info.Path = path
+	return info, nil
+}
+
+// List 列举目录下的文件
+// 游标为GCS返回的 nextPageToken
+func (fs *GcsFilesystem) List(ctx context.Context, prefix string, opts types.ListOptions) (types.ListResult, error) {
+	prefix = objstore.Key(prefix)
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	query := &storage.Query{Prefix: prefix}
+	if !opts.Recursive {
+		query.Delimiter = "/"
+	}
+	if err := query.SetAttrSelection([]string{"Name", "Size", "Updated", "ContentType", "Etag"}); err != nil {
+		return types.ListResult{}, err
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	var items []*storage.ObjectAttrs
+	next, err := iterator.NewPager(fs.client.Bucket(fs.Bucket.Name).Objects(ctx, query), limit, opts.Cursor).NextPage(&items)
+	if err != nil {
+		return types.ListResult{}, convertError("list", prefix, err)
+	}
+
+	// 与其他驱动一致，目录排在文件之前
+	result := types.ListResult{NextCursor: next}
+	for _, item := range items {
+		if item.Prefix != "" {
+			result.Files = append(result.Files, types.FileInfo{Path: item.Prefix, IsDir: true})
+		}
+	}
+	for _, item := range items {
+		if item.Prefix == "" {
+			result.Files = append(result.Files, toFileInfo(item))
+		}
+	}
+	return result, nil
+}
+
+// Copy 复制文件 使用GCS的服务端复制(rewrite)
+func (fs *GcsFilesystem) Copy(ctx context.Context, src, dst string, overwrite bool) error {
+	return fs.copy(ctx, "copy", src, dst, overwrite)
+}
+
+// Move 移动文件 GCS不支持移动，使用服务端复制后删除源文件
+func (fs *GcsFilesystem) Move(ctx context.Context, src, dst string, overwrite bool) error {
+	if err := fs.copy(ctx, "move", src, dst, overwrite); err != nil {
+		return err
+	}
+	return convertError("move", src, fs.object(objstore.Key(src)).Delete(ctx))
+}
+
+// copy 不覆盖时使用 ifGenerationMatch=0 条件，由服务端保证目标文件不存在
+func (fs *GcsFilesystem) copy(ctx context.Context, op, src, dst string, overwrite bool) error {
+	target := fs.object(objstore.Key(dst))
+	if !overwrite {
+		target = target.If(storage.Conditions{DoesNotExist: true})
+	}
+
+	_, err := target.CopierFrom(fs.object(objstore.Key(src))).Run(ctx)
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed {
+		return types.NewPathError(op, dst, types.ErrAlreadyExists, err)
+	}
+	return convertError(op, src, err)
+}
+
+// Delete 删除文件
+func (fs *GcsFilesystem) Delete(path string) error {
+	return fs.DeleteWithContext(context.Background(), path)
+}
+
+// DeleteWithContext 删除文件
+func (fs *GcsFilesystem) DeleteWithContext(ctx context.Context, path string) error {
+	return convertError("delete", path, fs.object(objstore.Key(path)).Delete(ctx))
+}
+
+// Exists 判断文件是否存在
+func (fs *GcsFilesystem) Exists(path string) bool {
+	return fs.ExistsWithContext(context.Background(), path)
+}
+
+// ExistsWithContext 判断文件是否存在 无法判断时返回 false，需要区分时使用 ExistsE
+func (fs *GcsFilesystem) ExistsWithContext(ctx context.Context, path string) bool {
+	exists, _ := fs.ExistsE(ctx, path)
+	return exists
+}
+
+// ExistsE 判断文件是否存在
+// 文件不存在时返回 false 和 nil，认证失败、网络错误等无法判断的情况返回错误
+func (fs *GcsFilesystem) ExistsE(ctx context.Context, path string) (bool, error) {
+	_, err := fs.Stat(ctx, path)
+	if errors.Is(err, types.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Close 关闭客户端
+func (fs *GcsFilesystem) Close() error {
+	return fs.client.Close()
+}
+
+func (fs *GcsFilesystem) object(key string) *storage.ObjectHandle {
+	return fs.client.Bucket(fs.Bucket.Name).Object(key)
+}
+
+// chunkSize 已知大小不超过一个分块时按实际大小分配缓冲区
+func (fs *GcsFilesystem) chunkSize(size int64) int {
+	chunkSize := objstore.PartSize(int64(fs.Bucket.ChunkSize))
+	if size >= 0 && size < chunkSize {
+		chunkSize = size
+	}
+	return int(chunkSize)
+}
+
+func toFileInfo(attrs *storage.ObjectAttrs) types.FileInfo {
+	return types.FileInfo{
+		Path:         attrs.Name,
+		Size:         attrs.Size,
+		LastModified: attrs.Updated,
+		ContentType:  attrs.ContentType,
+		ETag:         attrs.Etag,
+	}
+}
+
+// convertError 将GCS返回的错误转换为通用错误
+func convertError(op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, storage.ErrObjectNotExist) || errors.Is(err, storage.ErrBucketNotExist) {
+		return types.NewPathError(op, path, types.ErrNotFound, err)
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case http.StatusNotFound:
+			return types.NewPathError(op, path, types.ErrNotFound, err)
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return types.NewPathError(op, path, types.ErrPermission, err)
+		}
+	}
+	return types.NewPathError(op, path, nil, err)
+}
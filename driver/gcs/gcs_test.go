@@ -0,0 +1,365 @@
+package gcs_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yu1ec/go-filesystem/driver/gcs"
+	"github.com/yu1ec/go-filesystem/internal/drivertest"
+	"github.com/yu1ec/go-filesystem/types"
+)
+
+const (
+	testBucket = "test-bucket"
+	testEmail  = "tester@test-project.iam.gserviceaccount.com"
+	testToken  = "test-token"
+)
+
+// fakeGcs 简单的GCS服务，只实现驱动用到的接口
+// 元数据和上传使用JSON API，下载使用XML API，令牌接口为 /token
+type fakeGcs struct {
+	mu         sync.Mutex
+	url        string
+	objects    drivertest.Objects
+	uploads    map[string]*fakeUpload
+	resumables int // 完成的断点续传上传次数
+}
+
+type fakeUpload struct {
+	name        string
+	contentType string
+	data        []byte
+}
+
+func newFakeGcs() *fakeGcs {
+	return &fakeGcs{
+		objects: make(drivertest.Objects),
+		uploads: make(map[string]*fakeUpload),
+	}
+}
+
+func (f *fakeGcs) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/token" {
+		writeJSON(w, map[string]any{"access_token": testToken, "token_type": "Bearer", "expires_in": 3600})
+		return
+	}
+	// 签名URL通过查询参数携带签名，其余请求通过 Bearer 令牌
+	q := r.URL.Query()
+	if r.Header.Get("Authorization") != "Bearer "+testToken && q.Get("X-Goog-Signature") == "" {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	path := r.URL.EscapedPath()
+	switch {
+	case strings.HasPrefix(path, "/upload/storage/v1/b/"+testBucket+"/o"):
+		f.upload(w, r)
+	case path == "/storage/v1/b/"+testBucket+"/o" && r.Method == http.MethodGet:
+		f.list(w, q)
+	case strings.HasPrefix(path, "/storage/v1/b/"+testBucket+"/o/"):
+		src, dst, isRewrite := strings.Cut(strings.TrimPrefix(path, "/storage/v1/b/"+testBucket+"/o/"), "/rewriteTo/b/"+testBucket+"/o/")
+		key, _ := url.PathUnescape(src)
+		switch {
+		case isRewrite && r.Method == http.MethodPost:
+			dstKey, _ := url.PathUnescape(dst)
+			obj, ok := f.objects[key]
+			if !ok {
+				writeError(w, http.StatusNotFound, "No such object: "+key)
+				return
+			}
+			if _, exists := f.objects[dstKey]; exists && q.Get("ifGenerationMatch") == "0" {
+				writeError(w, http.StatusPreconditionFailed, "At least one of the pre-conditions you specified did not hold.")
+				return
+			}
+			obj.ModTime = time.Now()
+			f.objects[dstKey] = obj
+			writeJSON(w, map[string]any{
+				"kind":                "storage#rewriteResponse",
+				"done":                true,
+				"objectSize":          strconv.Itoa(len(obj.Data)),
+				"totalBytesRewritten": strconv.Itoa(len(obj.Data)),
+				"resource":            objectResource(dstKey, obj),
+			})
+		case r.Method == http.MethodGet:
+			obj, ok := f.objects[key]
+			if !ok {
+				writeError(w, http.StatusNotFound, "No such object: "+key)
+				return
+			}
+			writeJSON(w, objectResource(key, obj))
+		case r.Method == http.MethodDelete:
+			if _, ok := f.objects[key]; !ok {
+				writeError(w, http.StatusNotFound, "No such object: "+key)
+				return
+			}
+			delete(f.objects, key)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeError(w, http.StatusNotImplemented, "NotImplemented")
+		}
+	case strings.HasPrefix(path, "/"+testBucket+"/") && r.Method == http.MethodGet:
+		key, _ := url.PathUnescape(strings.TrimPrefix(path, "/"+testBucket+"/"))
+		obj, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("X-Goog-Generation", "1")
+		obj.Serve(w, r)
+	default:
+		writeError(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+// upload 实现 multipart 和 resumable 两种上传方式
+func (f *fakeGcs) upload(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	switch {
+	case q.Get("uploadType") == "multipart":
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		reader := multipart.NewReader(r.Body, params["boundary"])
+		var meta struct {
+			Name        string `json:"name"`
+			ContentType string `json:"contentType"`
+		}
+		part, _ := reader.NextPart()
+		json.NewDecoder(part).Decode(&meta)
+		part, _ = reader.NextPart()
+		data, _ := io.ReadAll(part)
+		if meta.ContentType == "" {
+			meta.ContentType = part.Header.Get("Content-Type")
+		}
+		f.objects[meta.Name] = drivertest.NewObject(data, meta.ContentType)
+		writeJSON(w, objectResource(meta.Name, f.objects[meta.Name]))
+	case q.Get("uploadType") == "resumable" && q.Get("upload_id") == "":
+		var meta struct {
+			Name        string `json:"name"`
+			ContentType string `json:"contentType"`
+		}
+		json.NewDecoder(r.Body).Decode(&meta)
+		if meta.ContentType == "" {
+			meta.ContentType = r.Header.Get("X-Upload-Content-Type")
+		}
+		id := strconv.Itoa(len(f.uploads) + 1)
+		f.uploads[id] = &fakeUpload{name: meta.Name, contentType: meta.ContentType}
+		w.Header().Set("Location", f.url+"/upload/storage/v1/b/"+testBucket+"/o?uploadType=resumable&upload_id="+id)
+		w.WriteHeader(http.StatusOK)
+	case q.Get("uploadType") == "resumable":
+		upload, ok := f.uploads[q.Get("upload_id")]
+		if !ok {
+			writeError(w, http.StatusNotFound, "No such upload")
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		upload.data = append(upload.data, data...)
+		// Content-Range 为 bytes 0-262143/* 时还有后续分块，总大小确定时为最后一块
+		// 客户端请求了 X-GUploader-No-308，未完成时返回200并通过头部说明
+		if strings.HasSuffix(r.Header.Get("Content-Range"), "/*") {
+			w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(upload.data)-1))
+			w.Header().Set("X-Http-Status-Code-Override", "308")
+			return
+		}
+		delete(f.uploads, q.Get("upload_id"))
+		f.objects[upload.name] = drivertest.NewObject(upload.data, upload.contentType)
+		f.resumables++
+		writeJSON(w, objectResource(upload.name, f.objects[upload.name]))
+	default:
+		writeError(w, http.StatusBadRequest, "unsupported upload")
+	}
+}
+
+// list 实现 Objects: list，分页令牌为上一页最后一个对象名称或前缀
+func (f *fakeGcs) list(w http.ResponseWriter, q url.Values) {
+	maxResults, err := strconv.Atoi(q.Get("maxResults"))
+	if err != nil {
+		maxResults = 1000
+	}
+	keys, prefixes, next := f.objects.List(q.Get("prefix"), q.Get("delimiter"), q.Get("pageToken"), maxResults)
+
+	items := []map[string]any{}
+	for _, key := range keys {
+		items = append(items, objectResource(key, f.objects[key]))
+	}
+	result := map[string]any{"kind": "storage#objects", "items": items, "prefixes": prefixes}
+	if next != "" {
+		result["nextPageToken"] = next
+	}
+	writeJSON(w, result)
+}
+
+func objectResource(name string, obj drivertest.Object) map[string]any {
+	sum := md5.Sum(obj.Data)
+	return map[string]any{
+		"kind":        "storage#object",
+		"bucket":      testBucket,
+		"name":        name,
+		"size":        strconv.Itoa(len(obj.Data)),
+		"contentType": obj.ContentType,
+		"updated":     obj.ModTime.UTC().Format(time.RFC3339Nano),
+		"etag":        base64.StdEncoding.EncodeToString(sum[:]),
+		"generation":  "1",
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": status, "message": message}})
+}
+
+// serviceAccountJSON 生成测试用的服务账号密钥，令牌接口指向测试服务
+func serviceAccountJSON(t *testing.T, tokenUri string) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "test-project",
+		"private_key_id": "1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email":   testEmail,
+		"token_uri":      tokenUri,
+	})
+	return string(data)
+}
+
+func setupTestServer(t *testing.T) (*gcs.GcsFilesystem, *fakeGcs, string) {
+	fake := newFakeGcs()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	fake.url = server.URL
+
+	credentials := serviceAccountJSON(t, server.URL+"/token")
+	fs, err := gcs.NewStorage(credentials, gcs.Bucket{
+		Name:      testBucket,
+		Endpoint:  server.URL,
+		ChunkSize: 256 << 10,
+	})
+	if err != nil {
+		t.Fatalf("NewStorage失败：%v", err)
+	}
+	t.Cleanup(func() { fs.Close() })
+	return fs, fake, server.URL
+}
+
+func TestGcsFilesystem(t *testing.T) {
+	fs, fake, _ := setupTestServer(t)
+	drivertest.Run(t, fs)
+	ctx := context.Background()
+
+	t.Run("断点续传上传", func(t *testing.T) {
+		resumables := fake.resumables
+		data := bytes.Repeat([]byte("0123456789"), 60<<10)
+		if err := fs.PutStream(ctx, "large.bin", io.MultiReader(bytes.NewReader(data)), -1); err != nil {
+			t.Fatalf("PutStream失败：%v", err)
+		}
+		if fake.resumables != resumables+1 {
+			t.Errorf("超过分块大小时应使用断点续传上传")
+		}
+		if retrieved, _ := fs.Get("large.bin"); !bytes.Equal(retrieved, data) {
+			t.Errorf("断点续传上传的数据不匹配，长度：%d", len(retrieved))
+		}
+	})
+
+	t.Run("删除不存在的文件", func(t *testing.T) {
+		if err := fs.Delete("missing.txt"); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("期望 ErrNotFound，实际：%v", err)
+		}
+	})
+
+	t.Run("覆盖复制", func(t *testing.T) {
+		fs.Put(ctx, "overwrite/a.txt", []byte("新内容"))
+		fs.Put(ctx, "overwrite/b.txt", []byte("旧内容"))
+		if err := fs.Copy(ctx, "overwrite/a.txt", "overwrite/b.txt", true); err != nil {
+			t.Fatalf("覆盖复制失败：%v", err)
+		}
+		if data, _ := fs.Get("overwrite/b.txt"); string(data) != "新内容" {
+			t.Errorf("覆盖后的内容不匹配：%s", string(data))
+		}
+	})
+}
+
+func TestGcsFilesystem_GetUrl(t *testing.T) {
+	fs, _, endpoint := setupTestServer(t)
+	if url := fs.GetUrl("dir/a b.txt"); url != endpoint+"/"+testBucket+"/dir/a%20b.txt" {
+		t.Errorf("URL不正确：%s", url)
+	}
+}
+
+func TestGcsFilesystem_GetSignedUrl(t *testing.T) {
+	fs, _, _ := setupTestServer(t)
+	if err := fs.Put(context.Background(), "test.txt", []byte("测试数据")); err != nil {
+		t.Fatalf("Put失败：%v", err)
+	}
+
+	signedUrl, err := fs.GetSignedUrl("test.txt", 600)
+	if err != nil {
+		t.Fatalf("GetSignedUrl失败：%v", err)
+	}
+	u, err := url.Parse(signedUrl)
+	if err != nil {
+		t.Fatalf("无法解析签名URL：%v", err)
+	}
+	q := u.Query()
+	// 过期时间由SDK根据签名时刻计算，允许1秒误差
+	expires, _ := strconv.Atoi(q.Get("X-Goog-Expires"))
+	if q.Get("X-Goog-Algorithm") != "GOOG4-RSA-SHA256" || expires < 599 || expires > 600 || !strings.HasPrefix(q.Get("X-Goog-Credential"), testEmail+"/") {
+		t.Errorf("V4签名参数不正确：%s", signedUrl)
+	}
+
+	resp, err := http.Get(signedUrl)
+	if err != nil {
+		t.Fatalf("请求签名URL失败：%v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "测试数据" {
+		t.Errorf("签名URL获取的数据不匹配：%s", string(body))
+	}
+}
+
+func TestGcsFilesystem_Credentials(t *testing.T) {
+	if _, err := gcs.NewStorage(`{"type":"authorized_user"}`, gcs.Bucket{Name: testBucket}); err == nil {
+		t.Error("非服务账号凭据应该返回错误")
+	}
+	if _, err := gcs.NewStorage("not json", gcs.Bucket{Name: testBucket}); err == nil {
+		t.Error("无效的凭据应该返回错误")
+	}
+}
@@ -0,0 +1,25 @@
+package gcs
+
+import (
+	filesystem "github.com/yu1ec/go-filesystem"
+	"github.com/yu1ec/go-filesystem/config"
+)
+
+func init() {
+	filesystem.RegisterDriver("gcs", func(cfg any) (filesystem.Filesystem, error) {
+		var c config.GcsDriverConfig
+		if err := filesystem.DecodeConfig(cfg, &c); err != nil {
+			return nil, err
+		}
+		fs, err := NewStorage(c.Credentials, Bucket{
+			Name:      c.Bucket,
+			Endpoint:  c.Endpoint,
+			Domain:    c.Domain,
+			ChunkSize: c.ChunkSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return fs, nil
+	})
+}
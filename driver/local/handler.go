@@ -0,0 +1,88 @@
+package local
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Handler 返回提供签名URL访问的 http.Handler
+// 请求路径为文件路径，只有签名正确且未过期时才返回文件，支持 Range 和条件请求
+// 挂载在子路径时需要配合 http.StripPrefix 使用，未设置 SignKey 时拒绝所有请求
+func (fs *LocalFilesystem) Handler() http.Handler {
+	return http.HandlerFunc(fs.serveSigned)
+}
+
+func (fs *LocalFilesystem) serveSigned(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	query := r.URL.Query()
+	if !fs.verify(key, query.Get("expires"), query.Get("signature")) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	d, rel, err := fs.openDir("get", key, false)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	defer d.Close()
+	f, err := d.Open(rel)
+	if err != nil {
+		http.Error(w, http.StatusText(statusCode(err)), statusCode(err))
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	// ServeContent 根据 ETag 和修改时间处理条件请求，并根据扩展名或内容设置 Content-Type
+	w.Header().Set("ETag", toFileInfo(key, info).ETag)
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+// sign 计算文件路径和过期时间的签名
+func (fs *LocalFilesystem) sign(key, deadline string) string {
+	mac := hmac.New(sha256.New, []byte(fs.SignKey))
+	mac.Write([]byte(key + "\n" + deadline))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verify 校验签名和过期时间
+func (fs *LocalFilesystem) verify(key, deadline, signature string) bool {
+	if fs.SignKey == "" || signature == "" {
+		return false
+	}
+	expires, err := strconv.ParseInt(deadline, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(fs.sign(key, deadline)), []byte(signature))
+}
+
+// statusCode 打开文件失败时的状态码
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return http.StatusNotFound
+	case errors.Is(err, os.ErrPermission):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
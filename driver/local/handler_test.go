@@ -0,0 +1,130 @@
+package local_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/yu1ec/go-filesystem/driver/local"
+)
+
+func setupSignedServer(t *testing.T) (*local.LocalFilesystem, *httptest.Server) {
+	fs := local.NewStorage(t.TempDir(), "")
+	fs.SignKey = "test-key"
+
+	mux := http.NewServeMux()
+	mux.Handle("/files/", http.StripPrefix("/files", fs.Handler()))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	fs.BaseUrl = server.URL + "/files"
+
+	if err := fs.Put(context.Background(), "dir/a b.txt", []byte("0123456789")); err != nil {
+		t.Fatalf("Put失败：%v", err)
+	}
+	return fs, server
+}
+
+func TestLocalFilesystem_Handler(t *testing.T) {
+	fs, server := setupSignedServer(t)
+
+	get := func(t *testing.T, rawUrl string, header http.Header) (*http.Response, string) {
+		req, _ := http.NewRequest(http.MethodGet, rawUrl, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("请求失败：%v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	signedUrl, err := fs.GetSignedUrl("/dir/a b.txt", 60)
+	if err != nil {
+		t.Fatalf("GetSignedUrl失败：%v", err)
+	}
+
+	t.Run("签名URL", func(t *testing.T) {
+		u, _ := url.Parse(signedUrl)
+		if u.Path != "/files/dir/a b.txt" || u.Query().Get("expires") == "" || u.Query().Get("signature") == "" {
+			t.Fatalf("签名URL不正确：%s", signedUrl)
+		}
+		resp, body := get(t, signedUrl, nil)
+		if resp.StatusCode != http.StatusOK || body != "0123456789" {
+			t.Errorf("期望200和文件内容，实际：%d %s", resp.StatusCode, body)
+		}
+		if resp.Header.Get("Content-Type") != "text/plain; charset=utf-8" || resp.Header.Get("ETag") == "" {
+			t.Errorf("响应头不正确：%v", resp.Header)
+		}
+	})
+
+	t.Run("拒绝无效签名", func(t *testing.T) {
+		u, _ := url.Parse(signedUrl)
+		q := u.Query()
+
+		cases := map[string]string{
+			"未签名":  server.URL + "/files/dir/a%20b.txt",
+			"修改路径": server.URL + "/files/dir/other.txt?" + q.Encode(),
+			"签名错误": strings.Replace(signedUrl, "signature=", "signature=x", 1),
+		}
+		expired := url.Values{"expires": {"1"}, "signature": {q.Get("signature")}}
+		cases["已过期"] = server.URL + "/files/dir/a%20b.txt?" + expired.Encode()
+
+		other := local.NewStorage(t.TempDir(), fs.BaseUrl)
+		other.SignKey = "other-key"
+		cases["其他密钥"], _ = other.GetSignedUrl("dir/a b.txt", 60)
+
+		for name, rawUrl := range cases {
+			if resp, _ := get(t, rawUrl, nil); resp.StatusCode != http.StatusForbidden {
+				t.Errorf("%s 期望403，实际：%d", name, resp.StatusCode)
+			}
+		}
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		resp, body := get(t, signedUrl, http.Header{"Range": {"bytes=2-5"}})
+		if resp.StatusCode != http.StatusPartialContent || body != "2345" {
+			t.Errorf("期望206和部分内容，实际：%d %s", resp.StatusCode, body)
+		}
+		if resp.Header.Get("Content-Range") != "bytes 2-5/10" {
+			t.Errorf("Content-Range不正确：%s", resp.Header.Get("Content-Range"))
+		}
+	})
+
+	t.Run("条件请求", func(t *testing.T) {
+		resp, _ := get(t, signedUrl, nil)
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+
+		if resp, _ := get(t, signedUrl, http.Header{"If-None-Match": {etag}}); resp.StatusCode != http.StatusNotModified {
+			t.Errorf("If-None-Match 期望304，实际：%d", resp.StatusCode)
+		}
+		if resp, _ := get(t, signedUrl, http.Header{"If-Modified-Since": {lastModified}}); resp.StatusCode != http.StatusNotModified {
+			t.Errorf("If-Modified-Since 期望304，实际：%d", resp.StatusCode)
+		}
+	})
+
+	t.Run("文件不存在", func(t *testing.T) {
+		missingUrl, _ := fs.GetSignedUrl("missing.txt", 60)
+		if resp, _ := get(t, missingUrl, nil); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("期望404，实际：%d", resp.StatusCode)
+		}
+	})
+
+	t.Run("未设置密钥", func(t *testing.T) {
+		fs := local.NewStorage(t.TempDir(), "http://example.com")
+		if url, _ := fs.GetSignedUrl("a.txt", 60); url != "http://example.com/a.txt" {
+			t.Errorf("未设置密钥时应返回普通URL：%s", url)
+		}
+		rec := httptest.NewRecorder()
+		fs.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/a.txt", nil))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("未设置密钥时应拒绝请求，实际：%d", rec.Code)
+		}
+	})
+}
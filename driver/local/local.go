@@ -3,18 +3,49 @@ package local
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"image"
+	"io"
+	iofs "io/fs"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"net/url"
 	"os"
+	pathpkg "path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
+
+	"github.com/yu1ec/go-filesystem/internal/fsutil"
+	"github.com/yu1ec/go-filesystem/types"
 )
 
 type LocalFilesystem struct {
-	Root    string // 根目录
+	Root    string // 根目录 文件路径经过 .. 后不能超出根目录
 	BaseUrl string // 基础URL
+
+	// RestrictSymlinks 禁止通过符号链接访问根目录之外的文件
+	// 所有操作通过 os.Root 进行，指向根目录内的相对符号链接仍然可以使用，绝对路径的符号链接会被拒绝
+	RestrictSymlinks bool
+
+	FileMode os.FileMode // 新建文件的权限 为0时使用 DefaultFileMode
+	DirMode  os.FileMode // 新建目录的权限 为0时使用 DefaultDirMode
+	SyncDir  bool        // 写入后同步父目录，确保重命名在断电后仍然有效
+
+	// SignKey 签名密钥 设置后 GetSignedUrl 生成带过期时间的HMAC签名URL
+	// 签名URL需要通过 Handler 访问，BaseUrl 为 Handler 的访问地址
+	SignKey string
 }
 
+const (
+	DefaultFileMode os.FileMode = 0644 // 默认文件权限
+	DefaultDirMode  os.FileMode = 0755 // 默认目录权限
+)
+
 func NewStorage(root string, baseUrl string) *LocalFilesystem {
 	fs := &LocalFilesystem{
 		Root:    root,
@@ -24,13 +55,11 @@ func NewStorage(root string, baseUrl string) *LocalFilesystem {
 }
 
 func (fs *LocalFilesystem) Put(ctx context.Context, path string, data []byte) error {
-	// path包含了文件名，所以需要提取出路径的文件夹路径,然后进行创建
-	fullPath := filepath.Join(fs.Root, path)
-	dir := filepath.Dir(fullPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-	return os.WriteFile(fullPath, data, 0644)
+
+	return fs.writeFile(ctx, path, bytes.NewReader(data))
 }
 
 func (fs *LocalFilesystem) PutWithoutContext(path string, data []byte) error {
@@ -38,29 +67,183 @@ func (fs *LocalFilesystem) PutWithoutContext(path string, data []byte) error {
 }
 
 func (fs *LocalFilesystem) Get(path string) ([]byte, error) {
-	fullPath := filepath.Join(fs.Root, path)
-	return os.ReadFile(fullPath)
+	return fs.GetWithContext(context.Background(), path)
+}
+
+// GetWithContext 获取文件内容
+func (fs *LocalFilesystem) GetWithContext(ctx context.Context, path string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	d, rel, err := fs.openDir("get", path, false)
+	if err != nil {
+		return nil, err
+	}
+	defer d.Close()
+
+	data, err := d.ReadFile(rel)
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+	return data, nil
+}
+
+// PutStream 以流的方式写入文件
+func (fs *LocalFilesystem) PutStream(ctx context.Context, path string, reader io.Reader, size int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return fs.writeFile(ctx, path, reader)
+}
+
+// writeFile 先写入同目录下的临时文件，同步到磁盘后重命名为目标文件
+// 读取方只会看到旧文件或完整的新文件，写入失败时删除临时文件
+func (fs *LocalFilesystem) writeFile(ctx context.Context, path string, reader io.Reader) error {
+	d, rel, err := fs.openDir("put", path, true)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	// path包含了文件名，所以需要提取出路径的文件夹路径,然后进行创建
+	dir := filepath.Dir(rel)
+	if err := d.MkdirAll(dir, fs.dirMode()); err != nil {
+		return convertError("put", path, err)
+	}
+
+	f, tmpName, err := createTemp(d, rel)
+	if err != nil {
+		return convertError("put", path, err)
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			d.Remove(tmpName)
+		}
+	}()
+
+	if _, err = io.Copy(f, fsutil.ContextReader(ctx, reader)); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	// 临时文件的权限为 0600
+	if err = f.Chmod(fs.fileMode()); err != nil {
+		return convertError("put", path, err)
+	}
+	if err = f.Sync(); err != nil {
+		return convertError("put", path, err)
+	}
+	if err = f.Close(); err != nil {
+		return convertError("put", path, err)
+	}
+	if err = d.Rename(tmpName, rel); err != nil {
+		return convertError("put", path, err)
+	}
+
+	if fs.SyncDir {
+		if err := syncDir(d, dir); err != nil {
+			return convertError("put", path, err)
+		}
+	}
+	return nil
+}
+
+// createTemp 在 name 所在的目录中创建 .文件名.随机数.tmp 临时文件，权限为 0600
+// 与 os.CreateTemp 相同，名称冲突时重新生成
+func createTemp(d dir, name string) (*os.File, string, error) {
+	prefix := filepath.Join(filepath.Dir(name), "."+filepath.Base(name)+".")
+	for range 10000 {
+		tmpName := prefix + strconv.FormatUint(uint64(rand.Uint32()), 10) + ".tmp"
+		f, err := d.OpenFile(tmpName, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		if !errors.Is(err, os.ErrExist) {
+			return f, tmpName, err
+		}
+	}
+	return nil, "", &os.PathError{Op: "createtemp", Path: prefix + "*.tmp", Err: os.ErrExist}
+}
+
+// syncDir 同步目录，使目录中的新建和重命名持久化
+func syncDir(d dir, name string) error {
+	f, err := d.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
+
+func (fs *LocalFilesystem) fileMode() os.FileMode {
+	if fs.FileMode == 0 {
+		return DefaultFileMode
+	}
+	return fs.FileMode
+}
+
+func (fs *LocalFilesystem) dirMode() os.FileMode {
+	if fs.DirMode == 0 {
+		return DefaultDirMode
+	}
+	return fs.DirMode
+}
+
+// GetStream 以流的方式读取文件
+func (fs *LocalFilesystem) GetStream(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	d, rel, err := fs.openDir("get", path, false)
+	if err != nil {
+		return nil, err
+	}
+	// 关闭根目录后已打开的文件仍然可用
+	defer d.Close()
+
+	f, err := d.Open(rel)
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+	return f, nil
 }
 
 // GetUrl 获取文件完整路径
-// 当路径是绝对路径时，忽略Root配置
+// 绝对路径同样相对于Root，路径超出根目录时返回空字符串
 func (fs *LocalFilesystem) GetUrl(path string) string {
+	rel, ok := localPath(path)
+	if !ok {
+		return ""
+	}
+
+	fullPath := filepath.Join(fs.Root, rel)
 	if fs.BaseUrl != "" {
-		return strings.TrimRight(fs.BaseUrl, "/") + "/" + strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "./")
-	} else {
-		fullPath, err := filepath.Abs(filepath.Join(fs.Root, path))
-		if err != nil {
-			return path
-		}
+		return strings.TrimRight(fs.BaseUrl, "/") + "/" + strings.TrimPrefix(filepath.ToSlash(rel), "./")
+	}
+	absPath, err := filepath.Abs(fullPath)
+	if err != nil {
 		return fullPath
 	}
-
+	return absPath
 }
 
 // GetSignedUrl 获取签名URL
+// 未设置 SignKey 时直接返回 GetUrl，设置后在URL中附加过期时间和签名
+// expires: 过期时间 单位/秒
 func (fs *LocalFilesystem) GetSignedUrl(path string, expires int64) (string, error) {
-	// 这里我们简单返回一个 URL，实际应用中可能需要生成签名
-	return fs.GetUrl(path), nil
+	if fs.SignKey == "" {
+		return fs.GetUrl(path), nil
+	}
+	if fs.BaseUrl == "" {
+		return "", errors.New("base url is required for signed urls")
+	}
+
+	rel, ok := localPath(path)
+	if !ok || rel == "." {
+		return "", types.NewPathError("sign", path, types.ErrInvalidPath, nil)
+	}
+	key := filepath.ToSlash(rel)
+	deadline := strconv.FormatInt(time.Now().Unix()+expires, 10)
+	query := url.Values{"expires": {deadline}, "signature": {fs.sign(key, deadline)}}
+	return strings.TrimRight(fs.BaseUrl, "/") + "/" + (&url.URL{Path: key}).EscapedPath() + "?" + query.Encode(), nil
 }
 
 // MustGetSignedUrl 获取签名URL
@@ -73,7 +256,12 @@ func (fs *LocalFilesystem) MustGetSignedUrl(path string, expires int64) string {
 }
 
 func (fs *LocalFilesystem) GetImageWidthHeight(path string) (int, int, error) {
-	data, err := fs.Get(path)
+	return fs.GetImageWidthHeightWithContext(context.Background(), path)
+}
+
+// GetImageWidthHeightWithContext 获取图片的宽高
+func (fs *LocalFilesystem) GetImageWidthHeightWithContext(ctx context.Context, path string) (int, int, error) {
+	data, err := fs.GetWithContext(ctx, path)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -90,16 +278,375 @@ func (fs *LocalFilesystem) GetImageWidthHeight(path string) (int, int, error) {
 
 // Delete 删除文件
 func (fs *LocalFilesystem) Delete(path string) error {
-	fullPath := filepath.Join(fs.Root, path)
-	if err := os.Remove(fullPath); err != nil {
-		return fmt.Errorf("failed to delete file: %w", err)
+	return fs.DeleteWithContext(context.Background(), path)
+}
+
+// DeleteWithContext 删除文件
+func (fs *LocalFilesystem) DeleteWithContext(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	d, rel, err := fs.openDir("delete", path, false)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	if err := d.Remove(rel); err != nil {
+		return convertError("delete", path, err)
 	}
 	return nil
 }
 
 // Exists 判断文件是否存在
 func (fs *LocalFilesystem) Exists(path string) bool {
-	fullpath := filepath.Join(fs.Root, path)
-	_, err := os.Stat(fullpath)
-	return !os.IsNotExist(err)
+	return fs.ExistsWithContext(context.Background(), path)
+}
+
+// ExistsWithContext 判断文件是否存在 无法判断时返回 false，需要区分时使用 ExistsE
+func (fs *LocalFilesystem) ExistsWithContext(ctx context.Context, path string) bool {
+	exists, _ := fs.ExistsE(ctx, path)
+	return exists
+}
+
+// ExistsE 判断文件是否存在
+// 文件不存在时返回 false 和 nil，权限不足等无法判断的情况返回错误
+func (fs *LocalFilesystem) ExistsE(ctx context.Context, path string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	d, rel, err := fs.openDir("exists", path, false)
+	if errors.Is(err, types.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer d.Close()
+
+	_, err = d.Stat(rel)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return false, convertError("exists", path, err)
+}
+
+// Stat 获取文件信息
+// ETag 由修改时间和文件大小生成，不读取文件内容
+func (fs *LocalFilesystem) Stat(ctx context.Context, path string) (types.FileInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return types.FileInfo{}, err
+	}
+
+	d, rel, err := fs.openDir("stat", path, false)
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	defer d.Close()
+
+	info, err := d.Stat(rel)
+	if err != nil {
+		return types.FileInfo{}, convertError("stat", path, err)
+	}
+
+	fileInfo := toFileInfo(path, info)
+	fileInfo.ContentType = detectContentType(d, rel)
+	return fileInfo, nil
+}
+
+// List 列举目录下的文件
+// prefix 为目录路径，目录不存在时返回空列表
+func (fs *LocalFilesystem) List(ctx context.Context, prefix string, opts types.ListOptions) (types.ListResult, error) {
+	d, rel, err := fs.openDir("list", prefix, false)
+	if errors.Is(err, types.ErrNotFound) {
+		return types.ListResult{}, nil
+	}
+	if err != nil {
+		return types.ListResult{}, err
+	}
+	defer d.Close()
+
+	fsys, err := iofs.Sub(d.FS(), filepath.ToSlash(rel))
+	if err != nil {
+		return types.ListResult{}, convertError("list", prefix, err)
+	}
+	var files []types.FileInfo
+
+	if opts.Recursive {
+		err := iofs.WalkDir(fsys, ".", func(p string, d iofs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			files = append(files, toFileInfo(pathpkg.Join(prefix, p), info))
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return types.ListResult{}, convertError("list", prefix, err)
+		}
+		return types.Paginate(files, opts.Cursor, opts.Limit), nil
+	}
+
+	entries, err := iofs.ReadDir(fsys, ".")
+	if err != nil && !os.IsNotExist(err) {
+		return types.ListResult{}, convertError("list", prefix, err)
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, toFileInfo(pathpkg.Join(prefix, entry.Name()), info))
+	}
+	return types.Paginate(files, opts.Cursor, opts.Limit), nil
+}
+
+// toFileInfo 将 os.FileInfo 转换为通用的文件信息
+// 目录路径以 / 结尾，MIME类型仅根据扩展名判断
+func toFileInfo(path string, info os.FileInfo) types.FileInfo {
+	if info.IsDir() {
+		return types.FileInfo{
+			Path:         strings.TrimSuffix(path, "/") + "/",
+			LastModified: info.ModTime(),
+			IsDir:        true,
+		}
+	}
+
+	return types.FileInfo{
+		Path:         path,
+		Size:         info.Size(),
+		LastModified: info.ModTime(),
+		ContentType:  mime.TypeByExtension(filepath.Ext(path)),
+		ETag:         fsutil.ETag(info.ModTime(), info.Size()),
+	}
+}
+
+// detectContentType 获取文件的MIME类型
+// 优先根据扩展名判断，无法判断时读取文件头部内容进行嗅探
+func detectContentType(d dir, name string) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(name)); contentType != "" {
+		return contentType
+	}
+
+	f, err := d.Open(name)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	return http.DetectContentType(head[:n])
+}
+
+// Copy 复制文件
+func (fs *LocalFilesystem) Copy(ctx context.Context, src, dst string, overwrite bool) error {
+	if !overwrite {
+		exists, err := fs.ExistsE(ctx, dst)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return types.NewPathError("copy", dst, types.ErrAlreadyExists, nil)
+		}
+	}
+
+	d, srcRel, err := fs.openDir("copy", src, false)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	dstRel, ok := localPath(dst)
+	if !ok {
+		return types.NewPathError("copy", dst, types.ErrInvalidPath, nil)
+	}
+	if srcRel == dstRel {
+		return nil
+	}
+
+	f, err := d.Open(srcRel)
+	if err != nil {
+		return convertError("copy", src, err)
+	}
+	defer f.Close()
+
+	return fs.PutStream(ctx, dst, f, -1)
+}
+
+// Move 移动文件
+// 使用 os.Rename 实现，跨设备时回退为复制后删除
+func (fs *LocalFilesystem) Move(ctx context.Context, src, dst string, overwrite bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !overwrite {
+		exists, err := fs.ExistsE(ctx, dst)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return types.NewPathError("move", dst, types.ErrAlreadyExists, nil)
+		}
+	}
+
+	d, srcRel, err := fs.openDir("move", src, false)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	dstRel, ok := localPath(dst)
+	if !ok {
+		return types.NewPathError("move", dst, types.ErrInvalidPath, nil)
+	}
+	if err := d.MkdirAll(filepath.Dir(dstRel), fs.dirMode()); err != nil {
+		return convertError("move", dst, err)
+	}
+
+	err = d.Rename(srcRel, dstRel)
+	if err == nil {
+		if fs.SyncDir {
+			return convertError("move", dst, syncDir(d, filepath.Dir(dstRel)))
+		}
+		return nil
+	}
+	if !errors.Is(err, syscall.EXDEV) {
+		return convertError("move", src, err)
+	}
+
+	if err := fs.Copy(ctx, src, dst, true); err != nil {
+		return err
+	}
+	return fs.DeleteWithContext(ctx, src)
+}
+
+// dir 根目录下的文件操作，名称为 localPath 返回的相对路径
+type dir interface {
+	Open(name string) (*os.File, error)
+	OpenFile(name string, flag int, perm os.FileMode) (*os.File, error)
+	ReadFile(name string) ([]byte, error)
+	Stat(name string) (os.FileInfo, error)
+	Remove(name string) error
+	Rename(oldname, newname string) error
+	MkdirAll(name string, perm os.FileMode) error
+	FS() iofs.FS
+	Close() error
+}
+
+// openDir 打开根目录，返回根目录和 path 的相对路径，使用完后需要关闭
+// RestrictSymlinks 为 true 时通过 os.Root 操作，打开文件时解析符号链接，检查和使用之间不会被替换
+// create 为 true 时根目录不存在则创建，否则返回 ErrNotFound
+func (fs *LocalFilesystem) openDir(op, path string, create bool) (dir, string, error) {
+	rel, ok := localPath(path)
+	if !ok {
+		return nil, "", types.NewPathError(op, path, types.ErrInvalidPath, nil)
+	}
+	root := fs.Root
+	if root == "" {
+		root = "."
+	}
+	if !fs.RestrictSymlinks {
+		return osDir(root), rel, nil
+	}
+
+	if create {
+		if err := os.MkdirAll(root, fs.dirMode()); err != nil {
+			return nil, "", convertError(op, path, err)
+		}
+	}
+	r, err := os.OpenRoot(root)
+	if err != nil {
+		return nil, "", convertError(op, path, err)
+	}
+	return r, rel, nil
+}
+
+// osDir 直接拼接路径操作，跟随所有符号链接
+type osDir string
+
+func (d osDir) path(name string) string {
+	return filepath.Join(string(d), name)
+}
+
+func (d osDir) Open(name string) (*os.File, error) {
+	return os.Open(d.path(name))
+}
+
+func (d osDir) OpenFile(name string, flag int, perm os.FileMode) (*os.File, error) {
+	return os.OpenFile(d.path(name), flag, perm)
+}
+
+func (d osDir) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(d.path(name))
+}
+
+func (d osDir) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(d.path(name))
+}
+
+func (d osDir) Remove(name string) error {
+	return os.Remove(d.path(name))
+}
+
+func (d osDir) Rename(oldname, newname string) error {
+	return os.Rename(d.path(oldname), d.path(newname))
+}
+
+func (d osDir) MkdirAll(name string, perm os.FileMode) error {
+	return os.MkdirAll(d.path(name), perm)
+}
+
+func (d osDir) FS() iofs.FS {
+	return os.DirFS(string(d))
+}
+
+func (d osDir) Close() error {
+	return nil
+}
+
+// localPath 去掉开头的分隔符后校验路径是否在根目录内，返回清理后的相对路径
+func localPath(path string) (string, bool) {
+	rel := filepath.Clean(filepath.FromSlash(strings.TrimLeft(filepath.ToSlash(path), "/")))
+	return rel, filepath.IsLocal(rel)
+}
+
+// convertError 将 os 包返回的错误转换为通用错误
+func convertError(op, path string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, os.ErrNotExist):
+		return types.NewPathError(op, path, types.ErrNotFound, err)
+	case errors.Is(err, os.ErrPermission):
+		return types.NewPathError(op, path, types.ErrPermission, err)
+	case errors.Is(err, os.ErrExist):
+		return types.NewPathError(op, path, types.ErrAlreadyExists, err)
+	case isEscape(err):
+		return types.NewPathError(op, path, types.ErrInvalidPath, err)
+	}
+	return types.NewPathError(op, path, nil, err)
+}
+
+// isEscape 判断是否为 os.Root 拒绝超出根目录的路径，os 包没有导出对应的错误
+func isEscape(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if err.Error() == "path escapes from parent" {
+			return true
+		}
+	}
+	return false
 }
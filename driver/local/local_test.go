@@ -3,14 +3,21 @@ package local_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"image"
 	"image/color"
 	"image/png"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/yu1ec/go-filesystem/driver/local"
+	"github.com/yu1ec/go-filesystem/internal/drivertest"
+	"github.com/yu1ec/go-filesystem/types"
 )
 
 func TestLocalFilesystem(t *testing.T) {
@@ -60,6 +67,31 @@ func TestLocalFilesystem(t *testing.T) {
 		}
 	})
 
+	t.Run("PutStream和GetStream", func(t *testing.T) {
+		path := "stream/test_stream.txt"
+		data := []byte("流式测试数据")
+
+		err := fs.PutStream(context.Background(), path, bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("PutStream失败：%v", err)
+		}
+
+		reader, err := fs.GetStream(context.Background(), path)
+		if err != nil {
+			t.Fatalf("GetStream失败：%v", err)
+		}
+		defer reader.Close()
+
+		retrieved, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("读取流失败：%v", err)
+		}
+
+		if !bytes.Equal(retrieved, data) {
+			t.Errorf("获取的数据不匹配。期望：%s，实际：%s", string(data), string(retrieved))
+		}
+	})
+
 	t.Run("GetUrl", func(t *testing.T) {
 		// 创建测试文件
 		relativeFile := "test_url.txt"
@@ -203,6 +235,156 @@ func TestLocalFilesystem(t *testing.T) {
 		if err == nil {
 			t.Error("Expected error when deleting non-existent file, got nil")
 		}
+		if !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Stat", func(t *testing.T) {
+		testPath := "test_stat.txt"
+		testData := []byte("test file for stat")
+
+		err := fs.Put(context.Background(), testPath, testData)
+		if err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		info, err := fs.Stat(context.Background(), testPath)
+		if err != nil {
+			t.Fatalf("Stat failed: %v", err)
+		}
+
+		if info.Size != int64(len(testData)) {
+			t.Errorf("Stat size mismatch. Expected %d, got %d", len(testData), info.Size)
+		}
+		if info.LastModified.IsZero() {
+			t.Error("Expected LastModified to be set")
+		}
+		if info.ContentType != "text/plain; charset=utf-8" {
+			t.Errorf("Stat content type mismatch, got %s", info.ContentType)
+		}
+		if info.ETag == "" {
+			t.Error("Expected ETag to be set")
+		}
+
+		_, err = fs.Stat(context.Background(), "nonexistent.txt")
+		if !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound when stating non-existent file, got %v", err)
+		}
+
+		var pathErr *types.PathError
+		if !errors.As(err, &pathErr) || pathErr.Op != "stat" || pathErr.Path != "nonexistent.txt" {
+			t.Errorf("Expected PathError, got %#v", err)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		listFs := local.NewStorage(filepath.Join(tempDir, "list"), "")
+		for _, p := range []string{"a.txt", "b.txt", "sub/c.txt", "sub/deep/d.txt"} {
+			if err := listFs.Put(context.Background(), p, []byte(p)); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+		}
+
+		result, err := listFs.List(context.Background(), "", types.ListOptions{})
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if got := drivertest.ListPaths(result.Files); got != "a.txt,b.txt,sub/" {
+			t.Errorf("List mismatch, got %s", got)
+		}
+		if !result.Files[2].IsDir {
+			t.Error("Expected sub/ to be a directory")
+		}
+
+		result, err = listFs.List(context.Background(), "sub", types.ListOptions{Recursive: true})
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if got := drivertest.ListPaths(result.Files); got != "sub/c.txt,sub/deep/d.txt" {
+			t.Errorf("Recursive list mismatch, got %s", got)
+		}
+
+		// 分页
+		var pages []string
+		opts := types.ListOptions{Recursive: true, Limit: 3}
+		for {
+			result, err := listFs.List(context.Background(), "", opts)
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			pages = append(pages, drivertest.ListPaths(result.Files))
+			if result.NextCursor == "" {
+				break
+			}
+			opts.Cursor = result.NextCursor
+		}
+		if len(pages) != 2 || pages[0] != "a.txt,b.txt,sub/c.txt" || pages[1] != "sub/deep/d.txt" {
+			t.Errorf("Paginated list mismatch, got %v", pages)
+		}
+
+		result, err = listFs.List(context.Background(), "nonexistent", types.ListOptions{})
+		if err != nil || len(result.Files) != 0 {
+			t.Errorf("Expected empty result for non-existent directory, got %v, %v", result.Files, err)
+		}
+	})
+
+	t.Run("Copy和Move", func(t *testing.T) {
+		data := []byte("copy and move")
+		if err := fs.Put(context.Background(), "copy/src.txt", data); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+
+		if err := fs.Copy(context.Background(), "copy/src.txt", "copy/dst/copied.txt", false); err != nil {
+			t.Fatalf("Copy失败：%v", err)
+		}
+		copied, err := fs.Get("copy/dst/copied.txt")
+		if err != nil || !bytes.Equal(copied, data) {
+			t.Errorf("复制后的数据不匹配：%s, %v", copied, err)
+		}
+
+		err = fs.Copy(context.Background(), "copy/src.txt", "copy/dst/copied.txt", false)
+		if !errors.Is(err, types.ErrAlreadyExists) {
+			t.Errorf("期望返回ErrAlreadyExists，实际：%v", err)
+		}
+
+		err = fs.Move(context.Background(), "copy/src.txt", "copy/dst/copied.txt", false)
+		if !errors.Is(err, types.ErrAlreadyExists) {
+			t.Errorf("期望返回ErrAlreadyExists，实际：%v", err)
+		}
+
+		if err := fs.Move(context.Background(), "copy/src.txt", "copy/moved/moved.txt", false); err != nil {
+			t.Fatalf("Move失败：%v", err)
+		}
+		if fs.Exists("copy/src.txt") {
+			t.Error("移动后源文件仍然存在")
+		}
+		moved, err := fs.Get("copy/moved/moved.txt")
+		if err != nil || !bytes.Equal(moved, data) {
+			t.Errorf("移动后的数据不匹配：%s, %v", moved, err)
+		}
+
+		if err := fs.Move(context.Background(), "copy/moved/moved.txt", "copy/dst/copied.txt", true); err != nil {
+			t.Fatalf("覆盖Move失败：%v", err)
+		}
+	})
+
+	t.Run("取消上下文", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if err := fs.Put(ctx, "canceled.txt", []byte("data")); !errors.Is(err, context.Canceled) {
+			t.Errorf("期望Put返回context.Canceled，实际：%v", err)
+		}
+		if fs.Exists("canceled.txt") {
+			t.Error("上下文取消后不应写入文件")
+		}
+		if _, err := fs.GetWithContext(ctx, "test.txt"); !errors.Is(err, context.Canceled) {
+			t.Errorf("期望GetWithContext返回context.Canceled，实际：%v", err)
+		}
+		if err := fs.DeleteWithContext(ctx, "test.txt"); !errors.Is(err, context.Canceled) {
+			t.Errorf("期望DeleteWithContext返回context.Canceled，实际：%v", err)
+		}
 	})
 
 	t.Run("Exists", func(t *testing.T) {
@@ -230,4 +412,195 @@ func TestLocalFilesystem(t *testing.T) {
 			t.Error("Expected file to not exist, but it does")
 		}
 	})
+
+	t.Run("ExistsE", func(t *testing.T) {
+		if err := fs.Put(context.Background(), "exists_e.txt", []byte("data")); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		exists, err := fs.ExistsE(context.Background(), "exists_e.txt")
+		if err != nil || !exists {
+			t.Errorf("Expected file to exist, got %v, %v", exists, err)
+		}
+
+		exists, err = fs.ExistsE(context.Background(), "nonexistent.txt")
+		if err != nil || exists {
+			t.Errorf("Expected file to not exist without error, got %v, %v", exists, err)
+		}
+
+		// 父路径是文件时无法判断，需要返回错误而不是 true
+		exists, err = fs.ExistsE(context.Background(), "exists_e.txt/child.txt")
+		if err == nil || exists {
+			t.Errorf("Expected error, got %v, %v", exists, err)
+		}
+		if fs.Exists("exists_e.txt/child.txt") {
+			t.Error("Expected Exists to return false when it can't tell")
+		}
+	})
+
+	t.Run("路径越界", func(t *testing.T) {
+		ctx := context.Background()
+		outside := filepath.Base(tempDir) + "_outside.txt"
+		for _, path := range []string{"../" + outside, "a/../../" + outside, "/../" + outside} {
+			if err := fs.Put(ctx, path, []byte("x")); !errors.Is(err, types.ErrInvalidPath) {
+				t.Errorf("Put %s 期望 ErrInvalidPath，实际：%v", path, err)
+			}
+			if _, err := fs.Get(path); !errors.Is(err, types.ErrInvalidPath) {
+				t.Errorf("Get %s 期望 ErrInvalidPath，实际：%v", path, err)
+			}
+			if err := fs.Delete(path); !errors.Is(err, types.ErrInvalidPath) {
+				t.Errorf("Delete %s 期望 ErrInvalidPath，实际：%v", path, err)
+			}
+			if exists, err := fs.ExistsE(ctx, path); exists || !errors.Is(err, types.ErrInvalidPath) {
+				t.Errorf("ExistsE %s 期望 ErrInvalidPath，实际：%v", path, err)
+			}
+			if url := fs.GetUrl(path); url != "" {
+				t.Errorf("GetUrl %s 期望空字符串，实际：%s", path, url)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(tempDir), outside)); !os.IsNotExist(err) {
+			t.Error("根目录之外不应创建文件")
+		}
+
+		// 没有超出根目录的 .. 仍然可以使用
+		if err := fs.Put(ctx, "a/../inside.txt", []byte("x")); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+		if !fs.Exists("inside.txt") {
+			t.Error("文件应该存在")
+		}
+	})
+}
+
+func TestLocalFilesystem_RestrictSymlinks(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	ctx := context.Background()
+	os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644)
+	os.WriteFile(filepath.Join(root, "target.txt"), []byte("target"), 0644)
+	for link, target := range map[string]string{
+		"escape":     outside,
+		"escape.txt": filepath.Join(outside, "secret.txt"),
+		"dangling":   filepath.Join(outside, "missing.txt"),
+		"inside.txt": "target.txt",
+		"inside_rel": ".",
+	} {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Skipf("无法创建符号链接：%v", err)
+		}
+	}
+
+	t.Run("默认跟随符号链接", func(t *testing.T) {
+		fs := local.NewStorage(root, "")
+		if data, err := fs.Get("escape/secret.txt"); err != nil || string(data) != "secret" {
+			t.Errorf("Get失败：%s %v", string(data), err)
+		}
+	})
+
+	fs := local.NewStorage(root, "")
+	fs.RestrictSymlinks = true
+
+	t.Run("拒绝指向根目录之外的链接", func(t *testing.T) {
+		if _, err := fs.Get("escape/secret.txt"); !errors.Is(err, types.ErrInvalidPath) {
+			t.Errorf("Get期望 ErrInvalidPath，实际：%v", err)
+		}
+		if _, err := fs.Get("escape.txt"); !errors.Is(err, types.ErrInvalidPath) {
+			t.Errorf("Get期望 ErrInvalidPath，实际：%v", err)
+		}
+		if err := fs.Put(ctx, "escape/new.txt", []byte("x")); !errors.Is(err, types.ErrInvalidPath) {
+			t.Errorf("Put期望 ErrInvalidPath，实际：%v", err)
+		}
+		if err := fs.Delete("escape/secret.txt"); !errors.Is(err, types.ErrInvalidPath) {
+			t.Errorf("Delete期望 ErrInvalidPath，实际：%v", err)
+		}
+		if _, err := os.Stat(filepath.Join(outside, "new.txt")); !os.IsNotExist(err) {
+			t.Error("根目录之外不应创建文件")
+		}
+		if _, err := os.Stat(filepath.Join(outside, "secret.txt")); err != nil {
+			t.Error("根目录之外的文件不应被删除")
+		}
+	})
+
+	t.Run("覆盖悬空的链接", func(t *testing.T) {
+		// 写入时重命名临时文件，替换的是链接本身
+		if err := fs.Put(ctx, "dangling", []byte("x")); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+		if info, err := os.Lstat(filepath.Join(root, "dangling")); err != nil || !info.Mode().IsRegular() {
+			t.Errorf("链接应该被替换为普通文件：%v", err)
+		}
+		if _, err := os.Stat(filepath.Join(outside, "missing.txt")); !os.IsNotExist(err) {
+			t.Error("根目录之外不应创建文件")
+		}
+	})
+
+	t.Run("允许指向根目录内的链接", func(t *testing.T) {
+		if data, err := fs.Get("inside.txt"); err != nil || string(data) != "target" {
+			t.Errorf("Get失败：%s %v", string(data), err)
+		}
+		if !fs.Exists("inside_rel/target.txt") {
+			t.Error("文件应该存在")
+		}
+		if err := fs.Put(ctx, "new/dir/file.txt", []byte("x")); err != nil {
+			t.Errorf("Put失败：%v", err)
+		}
+	})
+}
+
+func TestLocalFilesystem_AtomicWrite(t *testing.T) {
+	root := t.TempDir()
+	ctx := context.Background()
+	fs := local.NewStorage(root, "")
+	fs.FileMode = 0600
+	fs.DirMode = 0700
+	fs.SyncDir = true
+
+	t.Run("权限", func(t *testing.T) {
+		if err := fs.Put(ctx, "dir/a.txt", []byte("旧内容")); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+		if runtime.GOOS == "windows" {
+			t.Skip("Windows不支持Unix权限")
+		}
+		if info, _ := os.Stat(filepath.Join(root, "dir/a.txt")); info.Mode().Perm() != 0600 {
+			t.Errorf("文件权限不正确：%v", info.Mode().Perm())
+		}
+		if info, _ := os.Stat(filepath.Join(root, "dir")); info.Mode().Perm()&^0700 != 0 {
+			t.Errorf("目录权限不正确：%v", info.Mode().Perm())
+		}
+	})
+
+	t.Run("写入失败保留旧文件", func(t *testing.T) {
+		reader := io.MultiReader(strings.NewReader("新内容的一部分"), iotest.ErrReader(errors.New("读取失败")))
+		if err := fs.PutStream(ctx, "dir/a.txt", reader, -1); err == nil {
+			t.Fatal("期望返回错误")
+		}
+		if data, _ := fs.Get("dir/a.txt"); string(data) != "旧内容" {
+			t.Errorf("写入失败后旧文件应保持不变，实际：%s", string(data))
+		}
+
+		if err := fs.Put(ctx, "dir/a.txt", []byte("新内容")); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+		if data, _ := fs.Get("dir/a.txt"); string(data) != "新内容" {
+			t.Errorf("覆盖写入后的内容不匹配：%s", string(data))
+		}
+
+		// 临时文件在成功或失败后都应被清理
+		entries, _ := os.ReadDir(filepath.Join(root, "dir"))
+		if len(entries) != 1 {
+			t.Errorf("目录中不应残留临时文件：%v", entries)
+		}
+	})
+}
+
+func TestLocalFilesystem_Conformance(t *testing.T) {
+	drivertest.Run(t, local.NewStorage(t.TempDir(), ""))
+
+	// 通过 os.Root 操作时行为应该一致，根目录在首次写入时创建
+	t.Run("RestrictSymlinks", func(t *testing.T) {
+		fs := local.NewStorage(filepath.Join(t.TempDir(), "root"), "")
+		fs.RestrictSymlinks = true
+		drivertest.Run(t, fs)
+	})
 }
@@ -0,0 +1,331 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"image"
+	"io"
+	"mime"
+	"net/http"
+	pathpkg "path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yu1ec/go-filesystem/types"
+)
+
+// Hook 故障注入钩子，在每个操作执行前调用
+// op: 操作名称，与 PathError 的 Op 一致，如 put、get、stat、list、copy、move、delete、exists
+// 返回非 nil 的错误时，操作不会执行并直接返回该错误
+type Hook func(ctx context.Context, op, path string) error
+
+// MemoryFilesystem 内存文件系统，数据保存在进程内，主要用于测试
+type MemoryFilesystem struct {
+	BaseUrl string // 基础URL
+
+	mu      sync.RWMutex
+	files   map[string]*file
+	hook    Hook
+	latency time.Duration
+}
+
+type file struct {
+	data    []byte
+	modTime time.Time
+}
+
+func NewStorage(baseUrl string) *MemoryFilesystem {
+	return &MemoryFilesystem{
+		BaseUrl: baseUrl,
+		files:   make(map[string]*file),
+	}
+}
+
+// SetHook 设置故障注入钩子，传 nil 取消
+func (fs *MemoryFilesystem) SetHook(hook Hook) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.hook = hook
+}
+
+// SetLatency 设置每个操作的模拟延迟，延迟期间 ctx 取消时操作立即返回
+func (fs *MemoryFilesystem) SetLatency(latency time.Duration) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.latency = latency
+}
+
+// before 在操作执行前模拟延迟并调用钩子
+func (fs *MemoryFilesystem) before(ctx context.Context, op, path string) error {
+	fs.mu.RLock()
+	hook, latency := fs.hook, fs.latency
+	fs.mu.RUnlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if hook != nil {
+		return hook(ctx, op, path)
+	}
+	return nil
+}
+
+func (fs *MemoryFilesystem) Put(ctx context.Context, path string, data []byte) error {
+	if err := fs.before(ctx, "put", path); err != nil {
+		return err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.files[cleanPath(path)] = &file{
+		data:    bytes.Clone(data),
+		modTime: time.Now(),
+	}
+	return nil
+}
+
+func (fs *MemoryFilesystem) PutWithoutContext(path string, data []byte) error {
+	return fs.Put(context.Background(), path, data)
+}
+
+// PutStream 以流的方式写入文件 数据最终仍全部保存在内存中
+func (fs *MemoryFilesystem) PutStream(ctx context.Context, path string, reader io.Reader, size int64) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return types.NewPathError("put", path, nil, err)
+	}
+	return fs.Put(ctx, path, data)
+}
+
+func (fs *MemoryFilesystem) Get(path string) ([]byte, error) {
+	return fs.GetWithContext(context.Background(), path)
+}
+
+// GetWithContext 获取文件内容
+func (fs *MemoryFilesystem) GetWithContext(ctx context.Context, path string) ([]byte, error) {
+	if err := fs.before(ctx, "get", path); err != nil {
+		return nil, err
+	}
+
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	f, ok := fs.files[cleanPath(path)]
+	if !ok {
+		return nil, types.NewPathError("get", path, types.ErrNotFound, nil)
+	}
+	return bytes.Clone(f.data), nil
+}
+
+// GetStream 以流的方式读取文件
+func (fs *MemoryFilesystem) GetStream(ctx context.Context, path string) (io.ReadCloser, error) {
+	data, err := fs.GetWithContext(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// GetUrl 获取文件的URL
+func (fs *MemoryFilesystem) GetUrl(path string) string {
+	return strings.TrimRight(fs.BaseUrl, "/") + "/" + cleanPath(path)
+}
+
+// GetSignedUrl 获取签名URL 内存文件系统不需要签名，直接返回URL
+func (fs *MemoryFilesystem) GetSignedUrl(path string, expires int64) (string, error) {
+	return fs.GetUrl(path), nil
+}
+
+// MustGetSignedUrl 获取签名URL
+func (fs *MemoryFilesystem) MustGetSignedUrl(path string, expires int64) string {
+	url, err := fs.GetSignedUrl(path, expires)
+	if err != nil {
+		panic(err)
+	}
+	return url
+}
+
+func (fs *MemoryFilesystem) GetImageWidthHeight(path string) (int, int, error) {
+	return fs.GetImageWidthHeightWithContext(context.Background(), path)
+}
+
+// GetImageWidthHeightWithContext 获取图片的宽高
+func (fs *MemoryFilesystem) GetImageWidthHeightWithContext(ctx context.Context, path string) (int, int, error) {
+	data, err := fs.GetWithContext(ctx, path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, err
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// Stat 获取文件信息 ETag 为文件内容的MD5
+func (fs *MemoryFilesystem) Stat(ctx context.Context, path string) (types.FileInfo, error) {
+	if err := fs.before(ctx, "stat", path); err != nil {
+		return types.FileInfo{}, err
+	}
+
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	f, ok := fs.files[cleanPath(path)]
+	if !ok {
+		return types.FileInfo{}, types.NewPathError("stat", path, types.ErrNotFound, nil)
+	}
+	return f.info(path), nil
+}
+
+// List 列举目录下的文件
+// 内存中没有真实的目录，非递归时根据文件路径推断子目录
+func (fs *MemoryFilesystem) List(ctx context.Context, prefix string, opts types.ListOptions) (types.ListResult, error) {
+	if err := fs.before(ctx, "list", prefix); err != nil {
+		return types.ListResult{}, err
+	}
+
+	dir := cleanPath(prefix)
+	if dir != "" {
+		dir += "/"
+	}
+
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	var files []types.FileInfo
+	dirs := make(map[string]bool)
+	for key, f := range fs.files {
+		if !strings.HasPrefix(key, dir) {
+			continue
+		}
+
+		rel := strings.TrimPrefix(key, dir)
+		if i := strings.Index(rel, "/"); i >= 0 && !opts.Recursive {
+			dirs[dir+rel[:i+1]] = true
+			continue
+		}
+		files = append(files, f.info(key))
+	}
+	for d := range dirs {
+		files = append(files, types.FileInfo{Path: d, IsDir: true})
+	}
+
+	return types.Paginate(files, opts.Cursor, opts.Limit), nil
+}
+
+// Copy 复制文件
+func (fs *MemoryFilesystem) Copy(ctx context.Context, src, dst string, overwrite bool) error {
+	return fs.copyOrMove(ctx, "copy", src, dst, overwrite)
+}
+
+// Move 移动文件
+func (fs *MemoryFilesystem) Move(ctx context.Context, src, dst string, overwrite bool) error {
+	return fs.copyOrMove(ctx, "move", src, dst, overwrite)
+}
+
+func (fs *MemoryFilesystem) copyOrMove(ctx context.Context, op, src, dst string, overwrite bool) error {
+	if err := fs.before(ctx, op, src); err != nil {
+		return err
+	}
+
+	srcKey, dstKey := cleanPath(src), cleanPath(dst)
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	f, ok := fs.files[srcKey]
+	if !ok {
+		return types.NewPathError(op, src, types.ErrNotFound, nil)
+	}
+	if _, exists := fs.files[dstKey]; exists && !overwrite {
+		return types.NewPathError(op, dst, types.ErrAlreadyExists, nil)
+	}
+	if srcKey == dstKey {
+		return nil
+	}
+
+	fs.files[dstKey] = &file{data: f.data, modTime: time.Now()}
+	if op == "move" {
+		delete(fs.files, srcKey)
+	}
+	return nil
+}
+
+// Delete 删除文件
+func (fs *MemoryFilesystem) Delete(path string) error {
+	return fs.DeleteWithContext(context.Background(), path)
+}
+
+// DeleteWithContext 删除文件
+func (fs *MemoryFilesystem) DeleteWithContext(ctx context.Context, path string) error {
+	if err := fs.before(ctx, "delete", path); err != nil {
+		return err
+	}
+
+	key := cleanPath(path)
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if _, ok := fs.files[key]; !ok {
+		return types.NewPathError("delete", path, types.ErrNotFound, nil)
+	}
+	delete(fs.files, key)
+	return nil
+}
+
+// Exists 判断文件是否存在
+func (fs *MemoryFilesystem) Exists(path string) bool {
+	return fs.ExistsWithContext(context.Background(), path)
+}
+
+// ExistsWithContext 判断文件是否存在 无法判断时返回 false，需要区分时使用 ExistsE
+func (fs *MemoryFilesystem) ExistsWithContext(ctx context.Context, path string) bool {
+	exists, _ := fs.ExistsE(ctx, path)
+	return exists
+}
+
+// ExistsE 判断文件是否存在 钩子返回的错误会原样返回
+func (fs *MemoryFilesystem) ExistsE(ctx context.Context, path string) (bool, error) {
+	if err := fs.before(ctx, "exists", path); err != nil {
+		return false, err
+	}
+
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	_, ok := fs.files[cleanPath(path)]
+	return ok, nil
+}
+
+// info 生成文件信息，调用方需要持有锁
+func (f *file) info(path string) types.FileInfo {
+	contentType := mime.TypeByExtension(pathpkg.Ext(path))
+	if contentType == "" {
+		contentType = http.DetectContentType(f.data)
+	}
+
+	sum := md5.Sum(f.data)
+	return types.FileInfo{
+		Path:         path,
+		Size:         int64(len(f.data)),
+		LastModified: f.modTime,
+		ContentType:  contentType,
+		ETag:         `"` + hex.EncodeToString(sum[:]) + `"`,
+	}
+}
+
+// cleanPath 统一文件路径，去掉开头的 / 并处理 . 和 ..
+func cleanPath(path string) string {
+	return strings.TrimPrefix(pathpkg.Clean("/"+path), "/")
+}
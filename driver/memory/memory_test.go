@@ -0,0 +1,230 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yu1ec/go-filesystem/driver/memory"
+	"github.com/yu1ec/go-filesystem/internal/drivertest"
+	"github.com/yu1ec/go-filesystem/types"
+)
+
+func TestMemoryFilesystem(t *testing.T) {
+	drivertest.Run(t, memory.NewStorage(""))
+
+	fs := memory.NewStorage("http://example.com/")
+
+	t.Run("Put和Get", func(t *testing.T) {
+		data := []byte("测试数据")
+		if err := fs.Put(context.Background(), "test.txt", data); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+
+		retrieved, err := fs.Get("/test.txt")
+		if err != nil {
+			t.Fatalf("Get失败：%v", err)
+		}
+		if string(retrieved) != string(data) {
+			t.Errorf("获取的数据不匹配。期望：%s，实际：%s", string(data), string(retrieved))
+		}
+
+		// 修改返回的数据不应影响存储的内容
+		retrieved[0] = 'x'
+		again, _ := fs.Get("test.txt")
+		if string(again) != string(data) {
+			t.Errorf("存储的数据被外部修改：%s", string(again))
+		}
+	})
+
+	t.Run("PutStream和GetStream", func(t *testing.T) {
+		if err := fs.PutStream(context.Background(), "stream.txt", strings.NewReader("流数据"), -1); err != nil {
+			t.Fatalf("PutStream失败：%v", err)
+		}
+
+		rc, err := fs.GetStream(context.Background(), "stream.txt")
+		if err != nil {
+			t.Fatalf("GetStream失败：%v", err)
+		}
+		defer rc.Close()
+
+		data, _ := io.ReadAll(rc)
+		if string(data) != "流数据" {
+			t.Errorf("获取的数据不匹配：%s", string(data))
+		}
+	})
+
+	t.Run("GetUrl", func(t *testing.T) {
+		expected := "http://example.com/a/b.txt"
+		if url := fs.GetUrl("/a/b.txt"); url != expected {
+			t.Errorf("期望URL %s，实际 %s", expected, url)
+		}
+		if url := fs.MustGetSignedUrl("a/b.txt", 3600); url != expected {
+			t.Errorf("期望签名URL %s，实际 %s", expected, url)
+		}
+	})
+
+	t.Run("Stat", func(t *testing.T) {
+		info, err := fs.Stat(context.Background(), "test.txt")
+		if err != nil {
+			t.Fatalf("Stat失败：%v", err)
+		}
+		if info.Size != int64(len("测试数据")) {
+			t.Errorf("文件大小不匹配：%d", info.Size)
+		}
+		if !strings.HasPrefix(info.ContentType, "text/plain") {
+			t.Errorf("MIME类型不匹配：%s", info.ContentType)
+		}
+		if info.ETag == "" || info.LastModified.IsZero() {
+			t.Errorf("ETag或修改时间为空：%+v", info)
+		}
+
+		_, err = fs.Stat(context.Background(), "missing.txt")
+		var pathErr *types.PathError
+		if !errors.Is(err, types.ErrNotFound) || !errors.As(err, &pathErr) {
+			t.Errorf("期望 PathError 且为 ErrNotFound，实际：%v", err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		fs.Put(context.Background(), "delete.txt", []byte("x"))
+		if err := fs.Delete("delete.txt"); err != nil {
+			t.Fatalf("Delete失败：%v", err)
+		}
+		if fs.Exists("delete.txt") {
+			t.Error("文件应该已被删除")
+		}
+		if err := fs.Delete("delete.txt"); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("删除不存在的文件应返回 ErrNotFound，实际：%v", err)
+		}
+	})
+}
+
+func TestMemoryFilesystem_List(t *testing.T) {
+	fs := memory.NewStorage("")
+	for _, p := range []string{"dir/a.txt", "dir/b.txt", "dir/sub/c.txt", "dir/sub/deep/d.txt", "other.txt"} {
+		fs.Put(context.Background(), p, []byte(p))
+	}
+
+	t.Run("根目录", func(t *testing.T) {
+		result, err := fs.List(context.Background(), "", types.ListOptions{})
+		if err != nil {
+			t.Fatalf("List失败：%v", err)
+		}
+		if got := drivertest.ListPaths(result.Files); got != "dir/,other.txt" {
+			t.Errorf("实际 %s", got)
+		}
+	})
+}
+
+func TestMemoryFilesystem_CopyAndMove(t *testing.T) {
+	fs := memory.NewStorage("")
+	ctx := context.Background()
+	fs.Put(ctx, "src.txt", []byte("源文件"))
+	fs.Put(ctx, "exists.txt", []byte("已存在"))
+
+	if err := fs.Copy(ctx, "src.txt", "copy.txt", false); err != nil {
+		t.Fatalf("Copy失败：%v", err)
+	}
+	if data, _ := fs.Get("copy.txt"); string(data) != "源文件" {
+		t.Errorf("复制的内容不匹配：%s", string(data))
+	}
+
+	if err := fs.Copy(ctx, "src.txt", "exists.txt", false); !errors.Is(err, types.ErrAlreadyExists) {
+		t.Errorf("期望 ErrAlreadyExists，实际：%v", err)
+	}
+
+	if err := fs.Move(ctx, "src.txt", "exists.txt", true); err != nil {
+		t.Fatalf("Move失败：%v", err)
+	}
+	if fs.Exists("src.txt") {
+		t.Error("移动后源文件应不存在")
+	}
+	if data, _ := fs.Get("exists.txt"); string(data) != "源文件" {
+		t.Errorf("移动后的内容不匹配：%s", string(data))
+	}
+
+	if err := fs.Move(ctx, "src.txt", "dst.txt", false); !errors.Is(err, types.ErrNotFound) {
+		t.Errorf("期望 ErrNotFound，实际：%v", err)
+	}
+}
+
+func TestMemoryFilesystem_Hook(t *testing.T) {
+	fs := memory.NewStorage("")
+	ctx := context.Background()
+	fs.Put(ctx, "test.txt", []byte("data"))
+
+	injected := errors.New("injected")
+	fs.SetHook(func(ctx context.Context, op, path string) error {
+		if op == "get" || op == "exists" {
+			return types.NewPathError(op, path, types.ErrPermission, injected)
+		}
+		return nil
+	})
+
+	if _, err := fs.Get("test.txt"); !errors.Is(err, injected) || !errors.Is(err, types.ErrPermission) {
+		t.Errorf("Get 应返回注入的错误，实际：%v", err)
+	}
+	if exists, err := fs.ExistsE(ctx, "test.txt"); exists || !errors.Is(err, injected) {
+		t.Errorf("ExistsE 应返回注入的错误，实际：%v, %v", exists, err)
+	}
+	if _, err := fs.Stat(ctx, "test.txt"); err != nil {
+		t.Errorf("未注入的操作不应失败：%v", err)
+	}
+
+	fs.SetHook(nil)
+	if _, err := fs.Get("test.txt"); err != nil {
+		t.Errorf("取消钩子后 Get 不应失败：%v", err)
+	}
+}
+
+func TestMemoryFilesystem_Latency(t *testing.T) {
+	fs := memory.NewStorage("")
+	fs.SetLatency(50 * time.Millisecond)
+
+	start := time.Now()
+	if err := fs.Put(context.Background(), "test.txt", []byte("data")); err != nil {
+		t.Fatalf("Put失败：%v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("期望至少延迟50ms，实际：%v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := fs.GetWithContext(ctx, "test.txt"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期望 context.DeadlineExceeded，实际：%v", err)
+	}
+}
+
+func TestMemoryFilesystem_Concurrent(t *testing.T) {
+	fs := memory.NewStorage("")
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			path := fmt.Sprintf("dir/%d.txt", i)
+			fs.Put(ctx, path, []byte(path))
+			fs.Get(path)
+			fs.List(ctx, "dir", types.ListOptions{})
+			fs.Copy(ctx, path, path+".bak", true)
+		}(i)
+	}
+	wg.Wait()
+
+	result, err := fs.List(ctx, "dir", types.ListOptions{})
+	if err != nil {
+		t.Fatalf("List失败：%v", err)
+	}
+	if len(result.Files) != 40 {
+		t.Errorf("期望40个文件，实际：%d", len(result.Files))
+	}
+}
@@ -0,0 +1,367 @@
+package oss
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/yu1ec/go-filesystem/internal/objstore"
+	"github.com/yu1ec/go-filesystem/types"
+)
+
+// DefaultPartSize 默认分片大小，OSS 要求除最后一片外每片不小于 100KB
+const DefaultPartSize = objstore.DefaultPartSize
+
+type OssFilesystem struct {
+	AccessKeyId     string
+	AccessKeySecret string
+	Bucket          Bucket
+
+	client *oss.Client
+	bucket *oss.Bucket
+}
+
+// Bucket 存储桶
+type Bucket struct {
+	Name     string // 存储桶名称
+	Endpoint string // 地域节点 如 https://oss-cn-hangzhou.aliyuncs.com
+	Domain   string // 访问域名 如CDN域名，为空时使用存储桶的默认域名
+	PartSize int64  // 分片大小 超过此大小的文件使用分片上传，小于等于0时使用 DefaultPartSize
+}
+
+// NewStorage 创建阿里云OSS存储
+func NewStorage(accessKeyId, accessKeySecret string, bucket Bucket) (*OssFilesystem, error) {
+	client, err := oss.New(bucket.Endpoint, accessKeyId, accessKeySecret)
+	if err != nil {
+		return nil, err
+	}
+	ossBucket, err := client.Bucket(bucket.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OssFilesystem{
+		AccessKeyId:     accessKeyId,
+		AccessKeySecret: accessKeySecret,
+		Bucket:          bucket,
+		client:          client,
+		bucket:          ossBucket,
+	}, nil
+}
+
+func (fs *OssFilesystem) Put(ctx context.Context, path string, data []byte) error {
+	return fs.PutStream(ctx, path, bytes.NewReader(data), int64(len(data)))
+}
+
+func (fs *OssFilesystem) PutWithoutContext(path string, data []byte) error {
+	return fs.Put(context.Background(), path, data)
+}
+
+// PutStream 以流的方式写入文件
+// 数据不超过分片大小时直接上传，否则使用分片上传，内存占用不超过一个分片
+func (fs *OssFilesystem) PutStream(ctx context.Context, path string, reader io.Reader, size int64) error {
+	return convertError("put", path, objstore.PutStream(ctx, uploader{fs}, objstore.Key(path), reader, size, fs.Bucket.PartSize))
+}
+
+// uploader 实现 objstore.Uploader
+type uploader struct {
+	fs *OssFilesystem
+}
+
+func (u uploader) PutObject(ctx context.Context, key, contentType string, data []byte) error {
+	return u.fs.bucket.PutObject(key, bytes.NewReader(data), oss.WithContext(ctx), oss.ContentType(contentType))
+}
+
+func (u uploader) CreateMultipart(ctx context.Context, key, contentType string) (objstore.Multipart, error) {
+	imur, err := u.fs.bucket.InitiateMultipartUpload(key, oss.WithContext(ctx), oss.ContentType(contentType))
+	if err != nil {
+		return nil, err
+	}
+	return &multipart{bucket: u.fs.bucket, imur: imur}, nil
+}
+
+// multipart 进行中的分片上传
+type multipart struct {
+	bucket *oss.Bucket
+	imur   oss.InitiateMultipartUploadResult
+	parts  []oss.UploadPart
+}
+
+func (m *multipart) UploadPart(ctx context.Context, partNumber int, data []byte) error {
+	part, err := m.bucket.UploadPart(m.imur, bytes.NewReader(data), int64(len(data)), partNumber, oss.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	m.parts = append(m.parts, part)
+	return nil
+}
+
+func (m *multipart) Complete(ctx context.Context) error {
+	_, err := m.bucket.CompleteMultipartUpload(m.imur, m.parts, oss.WithContext(ctx))
+	return err
+}
+
+func (m *multipart) Abort(ctx context.Context) error {
+	return m.bucket.AbortMultipartUpload(m.imur, oss.WithContext(ctx))
+}
+
+func (fs *OssFilesystem) Get(path string) ([]byte, error) {
+	return fs.GetWithContext(context.Background(), path)
+}
+
+// GetWithContext 获取文件内容
+func (fs *OssFilesystem) GetWithContext(ctx context.Context, path string) ([]byte, error) {
+	body, err := fs.GetStream(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, types.NewPathError("get", path, nil, err)
+	}
+	return data, nil
+}
+
+// GetStream 以流的方式读取文件
+func (fs *OssFilesystem) GetStream(ctx context.Context, path string) (io.ReadCloser, error) {
+	body, err := fs.bucket.GetObject(objstore.Key(path), oss.WithContext(ctx))
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+	return body, nil
+}
+
+// GetStreamRange 从 offset 开始读取 length 字节，length 小于等于0时读取到文件末尾
+func (fs *OssFilesystem) GetStreamRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	rangeOption := oss.NormalizedRange(strconv.FormatInt(offset, 10) + "-")
+	if length > 0 {
+		rangeOption = oss.Range(offset, offset+length-1)
+	}
+	body, err := fs.bucket.GetObject(objstore.Key(path), oss.WithContext(ctx), rangeOption)
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+	return body, nil
+}
+
+// GetUrl 获取文件的URL
+// 未设置 Domain 时使用存储桶的默认域名
+func (fs *OssFilesystem) GetUrl(path string) string {
+	key := objstore.EscapeKey(objstore.Key(path))
+	if fs.Bucket.Domain != "" {
+		return strings.TrimRight(fs.Bucket.Domain, "/") + "/" + key
+	}
+
+	endpoint := fs.Bucket.Endpoint
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return strings.TrimRight(endpoint, "/") + "/" + fs.Bucket.Name + "/" + key
+	}
+	// 与SDK一致，IP形式的地址使用路径形式访问存储桶
+	if net.ParseIP(u.Hostname()) != nil {
+		return u.Scheme + "://" + u.Host + "/" + fs.Bucket.Name + "/" + key
+	}
+	return u.Scheme + "://" + fs.Bucket.Name + "." + u.Host + "/" + key
+}
+
+// GetSignedUrl 获取签名URL
+// path: 文件路径
+// expires: 过期时间 单位/秒
+func (fs *OssFilesystem) GetSignedUrl(path string, expires int64) (string, error) {
+	return fs.bucket.SignURL(objstore.Key(path), oss.HTTPGet, expires)
+}
+
+// MustGetSignedUrl 获取签名URL
+func (fs *OssFilesystem) MustGetSignedUrl(path string, expires int64) string {
+	url, err := fs.GetSignedUrl(path, expires)
+	if err != nil {
+		panic(err)
+	}
+	return url
+}
+
+func (fs *OssFilesystem) GetImageWidthHeight(path string) (int, int, error) {
+	return fs.GetImageWidthHeightWithContext(context.Background(), path)
+}
+
+// GetImageWidthHeightWithContext 获取图片的宽高
+// 使用OSS图片处理的 image/info，不需要下载图片
+func (fs *OssFilesystem) GetImageWidthHeightWithContext(ctx context.Context, path string) (int, int, error) {
+	body, err := fs.bucket.GetObject(objstore.Key(path), oss.WithContext(ctx), oss.Process("image/info"))
+	if err != nil {
+		return 0, 0, convertError("get", path, err)
+	}
+	defer body.Close()
+
+	var info struct {
+		ImageWidth  struct{ Value string } `json:"ImageWidth"`
+		ImageHeight struct{ Value string } `json:"ImageHeight"`
+	}
+	if err := json.NewDecoder(body).Decode(&info); err != nil {
+		return 0, 0, fmt.Errorf("failed to decode image info: %w", err)
+	}
+
+	width, err := strconv.Atoi(info.ImageWidth.Value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid image width: %w", err)
+	}
+	height, err := strconv.Atoi(info.ImageHeight.Value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid image height: %w", err)
+	}
+	return width, height, nil
+}
+
+// Stat 获取文件信息 通过HEAD请求获取
+func (fs *OssFilesystem) Stat(ctx context.Context, path string) (types.FileInfo, error) {
+	header, err := fs.bucket.GetObjectDetailedMeta(objstore.Key(path), oss.WithContext(ctx))
+	if err != nil {
+		return types.FileInfo{}, convertError("stat", path, err)
+	}
+
+	size, _ := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	lastModified, _ := http.ParseTime(header.Get("Last-Modified"))
+	return types.FileInfo{
+		Path:         path,
+		Size:         size,
+		LastModified: lastModified,
+		ContentType:  header.Get("Content-Type"),
+		ETag:         header.Get("ETag"),
+	}, nil
+}
+
+// List 列举目录下的文件
+// 使用 ListObjectsV2，游标为OSS返回的 ContinuationToken
+func (fs *OssFilesystem) List(ctx context.Context, prefix string, opts types.ListOptions) (types.ListResult, error) {
+	prefix = objstore.Key(prefix)
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	options := []oss.Option{oss.WithContext(ctx), oss.Prefix(prefix)}
+	if !opts.Recursive {
+		options = append(options, oss.Delimiter("/"))
+	}
+	if opts.Limit > 0 && opts.Limit < 1000 {
+		options = append(options, oss.MaxKeys(opts.Limit))
+	}
+	if opts.Cursor != "" {
+		options = append(options, oss.ContinuationToken(opts.Cursor))
+	}
+
+	ret, err := fs.bucket.ListObjectsV2(options...)
+	if err != nil {
+		return types.ListResult{}, convertError("list", prefix, err)
+	}
+
+	result := types.ListResult{}
+	for _, dir := range ret.CommonPrefixes {
+		result.Files = append(result.Files, types.FileInfo{Path: dir, IsDir: true})
+	}
+	for _, item := range ret.Objects {
+		result.Files = append(result.Files, types.FileInfo{
+			Path:         item.Key,
+			Size:         item.Size,
+			LastModified: item.LastModified,
+			ETag:         item.ETag,
+		})
+	}
+	if ret.IsTruncated {
+		result.NextCursor = ret.NextContinuationToken
+	}
+	return result, nil
+}
+
+// Copy 复制文件 使用OSS的服务端复制
+// 不覆盖时由服务端通过 x-oss-forbid-overwrite 判断目标文件是否存在
+func (fs *OssFilesystem) Copy(ctx context.Context, src, dst string, overwrite bool) error {
+	_, err := fs.bucket.CopyObject(objstore.Key(src), objstore.Key(dst), oss.WithContext(ctx), oss.ForbidOverWrite(!overwrite))
+	return convertCopyMoveError("copy", src, dst, err)
+}
+
+// Move 移动文件 OSS不支持移动，使用服务端复制后删除源文件
+func (fs *OssFilesystem) Move(ctx context.Context, src, dst string, overwrite bool) error {
+	_, err := fs.bucket.CopyObject(objstore.Key(src), objstore.Key(dst), oss.WithContext(ctx), oss.ForbidOverWrite(!overwrite))
+	if err != nil {
+		return convertCopyMoveError("move", src, dst, err)
+	}
+	return convertError("move", src, fs.bucket.DeleteObject(objstore.Key(src), oss.WithContext(ctx)))
+}
+
+// convertCopyMoveError 目标文件已存在的错误归属于目标文件，其余错误归属于源文件
+func convertCopyMoveError(op, src, dst string, err error) error {
+	var srvErr oss.ServiceError
+	if errors.As(err, &srvErr) && srvErr.Code == "FileAlreadyExists" {
+		return types.NewPathError(op, dst, types.ErrAlreadyExists, err)
+	}
+	return convertError(op, src, err)
+}
+
+// Delete 删除文件
+func (fs *OssFilesystem) Delete(path string) error {
+	return fs.DeleteWithContext(context.Background(), path)
+}
+
+// DeleteWithContext 删除文件
+// OSS删除不存在的文件不会返回错误，因此先查询元信息确认文件存在
+func (fs *OssFilesystem) DeleteWithContext(ctx context.Context, path string) error {
+	if _, err := fs.bucket.GetObjectMeta(objstore.Key(path), oss.WithContext(ctx)); err != nil {
+		return convertError("delete", path, err)
+	}
+	return convertError("delete", path, fs.bucket.DeleteObject(objstore.Key(path), oss.WithContext(ctx)))
+}
+
+// Exists 判断文件是否存在
+func (fs *OssFilesystem) Exists(path string) bool {
+	return fs.ExistsWithContext(context.Background(), path)
+}
+
+// ExistsWithContext 判断文件是否存在 无法判断时返回 false，需要区分时使用 ExistsE
+func (fs *OssFilesystem) ExistsWithContext(ctx context.Context, path string) bool {
+	exists, _ := fs.ExistsE(ctx, path)
+	return exists
+}
+
+// ExistsE 判断文件是否存在
+// 文件不存在时返回 false 和 nil，认证失败、网络错误等无法判断的情况返回错误
+func (fs *OssFilesystem) ExistsE(ctx context.Context, path string) (bool, error) {
+	exists, err := fs.bucket.IsObjectExist(objstore.Key(path), oss.WithContext(ctx))
+	if err != nil {
+		return false, convertError("exists", path, err)
+	}
+	return exists, nil
+}
+
+// convertError 将OSS返回的错误转换为通用错误
+func convertError(op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var srvErr oss.ServiceError
+	if errors.As(err, &srvErr) {
+		switch {
+		case srvErr.Code == "NoSuchKey" || srvErr.StatusCode == http.StatusNotFound:
+			return types.NewPathError(op, path, types.ErrNotFound, err)
+		case srvErr.Code == "FileAlreadyExists":
+			return types.NewPathError(op, path, types.ErrAlreadyExists, err)
+		case srvErr.StatusCode == http.StatusUnauthorized || srvErr.StatusCode == http.StatusForbidden:
+			return types.NewPathError(op, path, types.ErrPermission, err)
+		}
+	}
+	return types.NewPathError(op, path, nil, err)
+}
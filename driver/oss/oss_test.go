@@ -0,0 +1,282 @@
+package oss_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yu1ec/go-filesystem/driver/oss"
+	"github.com/yu1ec/go-filesystem/internal/drivertest"
+)
+
+const testBucket = "test-bucket"
+
+// fakeOss 简单的OSS服务，只实现驱动用到的接口
+// 使用IP地址访问时SDK使用路径形式，请求路径为 /bucket/key
+type fakeOss struct {
+	mu         sync.Mutex
+	objects    drivertest.Objects
+	uploads    map[string]map[int][]byte
+	multiparts int // 完成的分片上传次数
+}
+
+func newFakeOss() *fakeOss {
+	return &fakeOss{
+		objects: make(drivertest.Objects),
+		uploads: make(map[string]map[int][]byte),
+	}
+}
+
+func (f *fakeOss) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != testBucket {
+		drivertest.WriteXMLError(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	q := r.URL.Query()
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		f.list(w, q)
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		id := strconv.Itoa(len(f.uploads) + 1)
+		f.uploads[id] = make(map[int][]byte)
+		drivertest.WriteXML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: bucket, Key: key, UploadId: id})
+	case r.Method == http.MethodPut && q.Has("uploadId"):
+		parts, ok := f.uploads[q.Get("uploadId")]
+		if !ok {
+			drivertest.WriteXMLError(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		partNumber, _ := strconv.Atoi(q.Get("partNumber"))
+		parts[partNumber] = data
+		w.Header().Set("ETag", drivertest.Object{Data: data}.ETag())
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		parts, ok := f.uploads[q.Get("uploadId")]
+		if !ok {
+			drivertest.WriteXMLError(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		var complete struct {
+			Parts []struct{ PartNumber int } `xml:"Part"`
+		}
+		xml.NewDecoder(r.Body).Decode(&complete)
+		var data []byte
+		for _, part := range complete.Parts {
+			data = append(data, parts[part.PartNumber]...)
+		}
+		delete(f.uploads, q.Get("uploadId"))
+		f.objects[key] = drivertest.NewObject(data, r.Header.Get("Content-Type"))
+		f.multiparts++
+		drivertest.WriteXML(w, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Bucket  string
+			Key     string
+			ETag    string
+		}{Bucket: bucket, Key: key, ETag: drivertest.Object{Data: data}.ETag()})
+	case r.Method == http.MethodDelete && q.Has("uploadId"):
+		delete(f.uploads, q.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && r.Header.Get("X-Oss-Copy-Source") != "":
+		source, _ := url.QueryUnescape(r.Header.Get("X-Oss-Copy-Source"))
+		_, srcKey, _ := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+		obj, ok := f.objects[srcKey]
+		if !ok {
+			drivertest.WriteXMLError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		if _, exists := f.objects[key]; exists && r.Header.Get("X-Oss-Forbid-Overwrite") == "true" {
+			drivertest.WriteXMLError(w, http.StatusConflict, "FileAlreadyExists")
+			return
+		}
+		obj.ModTime = time.Now()
+		f.objects[key] = obj
+		drivertest.WriteXML(w, struct {
+			XMLName      xml.Name `xml:"CopyObjectResult"`
+			LastModified string
+			ETag         string
+		}{LastModified: obj.ModTime.UTC().Format(time.RFC3339), ETag: obj.ETag()})
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key] = drivertest.NewObject(data, r.Header.Get("Content-Type"))
+		w.Header().Set("ETag", drivertest.Object{Data: data}.ETag())
+	case r.Method == http.MethodGet && q.Get("x-oss-process") == "image/info":
+		obj, ok := f.objects[key]
+		if !ok {
+			drivertest.WriteXMLError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(obj.Data))
+		if err != nil {
+			drivertest.WriteXMLError(w, http.StatusBadRequest, "BadRequest")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"FileSize":{"value":"%d"},"Format":{"value":"%s"},"ImageHeight":{"value":"%d"},"ImageWidth":{"value":"%d"}}`,
+			len(obj.Data), format, cfg.Height, cfg.Width)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		obj, ok := f.objects[key]
+		if !ok {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			drivertest.WriteXMLError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		obj.Serve(w, r)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		drivertest.WriteXMLError(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+// list 实现 ListObjectsV2，游标为上一页最后一个key或公共前缀
+func (f *fakeOss) list(w http.ResponseWriter, q url.Values) {
+	maxKeys, err := strconv.Atoi(q.Get("max-keys"))
+	if err != nil {
+		maxKeys = 1000
+	}
+	keys, prefixes, next := f.objects.List(q.Get("prefix"), q.Get("delimiter"), q.Get("continuation-token"), maxKeys)
+
+	type content struct {
+		Key          string
+		LastModified string
+		ETag         string
+		Size         int
+	}
+	result := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Contents              []content
+		CommonPrefixes        []string `xml:"CommonPrefixes>Prefix"`
+		IsTruncated           bool
+		NextContinuationToken string `xml:",omitempty"`
+	}{CommonPrefixes: prefixes, IsTruncated: next != "", NextContinuationToken: next}
+	for _, key := range keys {
+		obj := f.objects[key]
+		result.Contents = append(result.Contents, content{
+			Key:          key,
+			LastModified: obj.ModTime.UTC().Format(time.RFC3339),
+			ETag:         obj.ETag(),
+			Size:         len(obj.Data),
+		})
+	}
+	drivertest.WriteXML(w, result)
+}
+
+func setupTestServer(t *testing.T) (*oss.OssFilesystem, *fakeOss) {
+	fake := newFakeOss()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	fs, err := oss.NewStorage("testkey", "testsecret", oss.Bucket{
+		Name:     testBucket,
+		Endpoint: server.URL,
+		PartSize: 1024,
+	})
+	if err != nil {
+		t.Fatalf("NewStorage失败：%v", err)
+	}
+	return fs, fake
+}
+
+func TestOssFilesystem(t *testing.T) {
+	fs, fake := setupTestServer(t)
+	drivertest.Run(t, fs)
+	ctx := context.Background()
+
+	t.Run("分片上传", func(t *testing.T) {
+		multiparts := fake.multiparts
+		data := bytes.Repeat([]byte("0123456789"), 350)
+		if err := fs.PutStream(ctx, "large.bin", bytes.NewReader(data), int64(len(data))); err != nil {
+			t.Fatalf("PutStream失败：%v", err)
+		}
+		if fake.multiparts != multiparts+1 {
+			t.Errorf("超过分片大小时应使用分片上传")
+		}
+		if retrieved, _ := fs.Get("large.bin"); !bytes.Equal(retrieved, data) {
+			t.Errorf("分片上传的数据不匹配，长度：%d", len(retrieved))
+		}
+	})
+
+	t.Run("覆盖复制", func(t *testing.T) {
+		fs.Put(ctx, "overwrite/a.txt", []byte("新内容"))
+		fs.Put(ctx, "overwrite/b.txt", []byte("旧内容"))
+		if err := fs.Copy(ctx, "overwrite/a.txt", "overwrite/b.txt", true); err != nil {
+			t.Fatalf("覆盖复制失败：%v", err)
+		}
+		if data, _ := fs.Get("overwrite/b.txt"); string(data) != "新内容" {
+			t.Errorf("覆盖后的内容不匹配：%s", string(data))
+		}
+	})
+}
+
+func TestOssFilesystem_GetUrl(t *testing.T) {
+	fs, _ := setupTestServer(t)
+	if url := fs.GetUrl("dir/a b.txt"); !strings.HasSuffix(url, "/"+testBucket+"/dir/a%20b.txt") {
+		t.Errorf("URL不正确：%s", url)
+	}
+
+	aliyun, err := oss.NewStorage("k", "s", oss.Bucket{Name: "bucket", Endpoint: "oss-cn-hangzhou.aliyuncs.com"})
+	if err != nil {
+		t.Fatalf("NewStorage失败：%v", err)
+	}
+	if url := aliyun.GetUrl("/a.txt"); url != "https://bucket.oss-cn-hangzhou.aliyuncs.com/a.txt" {
+		t.Errorf("URL不正确：%s", url)
+	}
+}
+
+func TestOssFilesystem_GetSignedUrl(t *testing.T) {
+	fs, _ := setupTestServer(t)
+	if err := fs.Put(context.Background(), "test.txt", []byte("测试数据")); err != nil {
+		t.Fatalf("Put失败：%v", err)
+	}
+
+	signedUrl, err := fs.GetSignedUrl("test.txt", 600)
+	if err != nil {
+		t.Fatalf("GetSignedUrl失败：%v", err)
+	}
+	u, err := url.Parse(signedUrl)
+	if err != nil {
+		t.Fatalf("无法解析签名URL：%v", err)
+	}
+	expires, _ := strconv.ParseInt(u.Query().Get("Expires"), 10, 64)
+	if diff := expires - time.Now().Unix(); diff < 590 || diff > 610 {
+		t.Errorf("过期时间不正确：%s", signedUrl)
+	}
+	if u.Query().Get("Signature") == "" {
+		t.Errorf("签名URL缺少签名：%s", signedUrl)
+	}
+
+	resp, err := http.Get(signedUrl)
+	if err != nil {
+		t.Fatalf("请求签名URL失败：%v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "测试数据" {
+		t.Errorf("签名URL获取的数据不匹配：%s", string(body))
+	}
+}
@@ -0,0 +1,26 @@
+package oss
+
+import (
+	filesystem "github.com/yu1ec/go-filesystem"
+	"github.com/yu1ec/go-filesystem/config"
+)
+
+func init() {
+	filesystem.RegisterDriver("oss", func(cfg any) (filesystem.Filesystem, error) {
+		var c config.OssDriverConfig
+		if err := filesystem.DecodeConfig(cfg, &c); err != nil {
+			return nil, err
+		}
+		bucket := Bucket{
+			Name:     c.Bucket,
+			Endpoint: c.Endpoint,
+			Domain:   c.Domain,
+			PartSize: c.PartSize,
+		}
+		fs, err := NewStorage(c.AccessKeyId, c.AccessKeySecret, bucket)
+		if err != nil {
+			return nil, err
+		}
+		return fs, nil
+	})
+}
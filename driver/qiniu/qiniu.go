@@ -15,6 +15,7 @@ import (
 	"github.com/qiniu/go-sdk/v7/auth"
 	"github.com/qiniu/go-sdk/v7/cdn"
 	"github.com/qiniu/go-sdk/v7/storage"
+	"github.com/yu1ec/go-filesystem/types"
 )
 
 type QiniuFilesystem struct {
@@ -126,51 +127,91 @@ func (qn *QiniuFilesystem) PutWithoutContext(path string, data []byte) error {
 }
 
 func (qn *QiniuFilesystem) Put(ctx context.Context, path string, data []byte) error {
-	uploadToken := qn.SimpleUploadToken(path, 180)
+	return qn.PutStream(ctx, path, bytes.NewReader(data), int64(len(data)))
+}
+
+// PutStream 以流的方式上传文件
+// size: 数据长度，未知时传 -1，此时使用分片上传
+func (qn *QiniuFilesystem) PutStream(ctx context.Context, path string, reader io.Reader, size int64) error {
+	// 大文件上传耗时较长，凭证有效期适当放宽
+	uploadToken := qn.SimpleUploadToken(path, 3600)
 	cfg := storage.Config{}
-	formUpload := storage.NewFormUploader(&cfg)
 
 	ret := storage.PutRet{}
 
-	putExtra := storage.PutExtra{}
+	if size < 0 {
+		resumeUpload := storage.NewResumeUploaderV2(&cfg)
+		err := resumeUpload.PutWithoutSize(ctx, &ret, uploadToken, path, reader, &storage.RputV2Extra{})
+		if err != nil {
+			return convertError("put", path, err)
+		}
+		return nil
+	}
+
+	formUpload := storage.NewFormUploader(&cfg)
 
-	dataLen := int64(len(data))
+	putExtra := storage.PutExtra{}
 
-	err := formUpload.Put(ctx, &ret, uploadToken, path, bytes.NewReader(data), dataLen, &putExtra)
+	err := formUpload.Put(ctx, &ret, uploadToken, path, reader, size, &putExtra)
 	if err != nil {
-		return fmt.Errorf("upload data failed, %w", err)
+		return convertError("put", path, err)
 	}
 
 	return nil
 }
 
-// Get 获取文件
+// Get 获取文件 超时时间为10秒
 func (qn *QiniuFilesystem) Get(path string) ([]byte, error) {
-	resURL, err := qn.GetSignedUrl(path, 180)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return qn.GetWithContext(ctx, path)
+}
+
+// GetWithContext 获取文件 超时和取消由 ctx 控制
+func (qn *QiniuFilesystem) GetWithContext(ctx context.Context, path string) ([]byte, error) {
+	reader, err := qn.GetStream(ctx, path)
 	if err != nil {
-		return nil, fmt.Errorf("fail to get signed url, %w", err)
+		return nil, err
 	}
+	defer reader.Close()
 
-	httpCli := &http.Client{
-		Timeout: 10 * time.Second,
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body, %w", err)
 	}
 
-	resp, err := httpCli.Get(resURL)
+	return body, nil
+}
+
+// GetStream 以流的方式获取文件
+// 返回的是响应体，不设置整体超时，调用方使用完毕后需要关闭
+func (qn *QiniuFilesystem) GetStream(ctx context.Context, path string) (io.ReadCloser, error) {
+	resp, err := qn.request(ctx, http.MethodGet, path)
 	if err != nil {
 		return nil, fmt.Errorf("fail to get file, %w", err)
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("fail to get file, status code: %d", resp.StatusCode)
+		resp.Body.Close()
+		return nil, convertStatusCode("get", path, resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	return resp.Body, nil
+}
+
+// request 使用签名URL请求文件
+func (qn *QiniuFilesystem) request(ctx context.Context, method, path string) (*http.Response, error) {
+	resURL, err := qn.GetSignedUrl(path, 180)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read body, %w", err)
+		return nil, fmt.Errorf("fail to get signed url, %w", err)
 	}
 
-	return body, nil
+	req, err := http.NewRequestWithContext(ctx, method, resURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fail to build request, %w", err)
+	}
+
+	return http.DefaultClient.Do(req)
 }
 
 // GetUrl 获取文件的URL
@@ -196,18 +237,17 @@ func (qn *QiniuFilesystem) MustGetSignedUrl(path string, expires int64) string {
 	return url
 }
 
-// GetImageWidthHeight 获取图片的宽高
+// GetImageWidthHeight 获取图片的宽高 超时时间为5秒
 func (qn *QiniuFilesystem) GetImageWidthHeight(path string) (width int, height int, err error) {
-	path = path + "?imageInfo"
-	url, err := qn.GetSignedUrl(path, 180)
-	if err != nil {
-		return 0, 0, err
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return qn.GetImageWidthHeightWithContext(ctx, path)
+}
 
-	httpCli := &http.Client{
-		Timeout: 5 * time.Second,
-	}
-	resp, err := httpCli.Get(url)
+// GetImageWidthHeightWithContext 获取图片的宽高
+func (qn *QiniuFilesystem) GetImageWidthHeightWithContext(ctx context.Context, path string) (width int, height int, err error) {
+	path = path + "?imageInfo"
+	resp, err := qn.request(ctx, http.MethodGet, path)
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to get image info, %w", err)
 	}
@@ -215,7 +255,7 @@ func (qn *QiniuFilesystem) GetImageWidthHeight(path string) (width int, height i
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return 0, 0, fmt.Errorf("failed to get image info, status code: %d", resp.StatusCode)
+		return 0, 0, convertStatusCode("imageInfo", path, resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -242,7 +282,7 @@ func (qn *QiniuFilesystem) getPrivateUrl(path string, expires int64) (string, er
 	// 从path中解析query,
 	uri, err := url.Parse(path)
 	if err != nil {
-		return "", errors.New("path is invalid")
+		return "", types.NewPathError("sign", path, types.ErrInvalidPath, err)
 	}
 	key := strings.TrimLeft(uri.Path, "/")
 
@@ -258,21 +298,142 @@ func (qn *QiniuFilesystem) getPrivateUrl(path string, expires int64) (string, er
 
 // Delete 删除文件
 func (qn *QiniuFilesystem) Delete(path string) error {
-	return qn.bucketManager.Delete(qn.Bucket.Name, path)
+	return qn.DeleteWithContext(context.Background(), path)
+}
+
+// DeleteWithContext 删除文件
+func (qn *QiniuFilesystem) DeleteWithContext(ctx context.Context, path string) error {
+	_, err := qn.batchOne(ctx, storage.URIDelete(qn.Bucket.Name, path))
+	return convertError("delete", path, err)
 }
 
 // Exists 判断文件是否存在
 func (qn *QiniuFilesystem) Exists(path string) bool {
-	signedUrl := qn.MustGetSignedUrl(path, 180)
+	return qn.ExistsWithContext(context.Background(), path)
+}
+
+// ExistsWithContext 判断文件是否存在 无法判断时返回 false，需要区分时使用 ExistsE
+func (qn *QiniuFilesystem) ExistsWithContext(ctx context.Context, path string) bool {
+	exists, _ := qn.ExistsE(ctx, path)
+	return exists
+}
 
-	// 只请求头信息，判断文件是否存在
-	resp, err := http.Head(signedUrl)
+// ExistsE 判断文件是否存在
+// 通过资源管理接口的 stat 查询，不经过CDN域名，私有空间同样适用
+// 文件不存在(612)时返回 false 和 nil，权限不足、网络错误等无法判断的情况返回错误
+func (qn *QiniuFilesystem) ExistsE(ctx context.Context, path string) (bool, error) {
+	_, err := qn.Stat(ctx, path)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, types.ErrNotFound) {
+		return false, nil
+	}
+	return false, err
+}
+
+// batchOne 通过批量接口执行单个资源管理操作
+// SDK 的 Stat、Delete、Copy、Move 不支持 context，批量接口支持，因此统一使用批量接口
+func (qn *QiniuFilesystem) batchOne(ctx context.Context, operation string) (storage.BatchOpRet, error) {
+	rets, err := qn.bucketManager.BatchWithContext(ctx, qn.Bucket.Name, []string{operation})
 	if err != nil {
-		return false
+		return storage.BatchOpRet{}, err
+	}
+	if len(rets) == 0 {
+		return storage.BatchOpRet{}, errors.New("empty batch response")
+	}
+
+	ret := rets[0]
+	if ret.Code != http.StatusOK {
+		return ret, &storage.ErrorInfo{Code: ret.Code, Err: ret.Data.Error}
+	}
+	return ret, nil
+}
+
+// Stat 获取文件信息
+// ETag 为七牛云计算的文件hash值
+func (qn *QiniuFilesystem) Stat(ctx context.Context, path string) (types.FileInfo, error) {
+	ret, err := qn.batchOne(ctx, storage.URIStat(qn.Bucket.Name, path))
+	if err != nil {
+		return types.FileInfo{}, convertError("stat", path, err)
 	}
-	defer resp.Body.Close()
 
-	return resp.StatusCode != http.StatusNotFound
+	return types.FileInfo{
+		Path:         path,
+		Size:         ret.Data.Fsize,
+		LastModified: storage.ParsePutTime(ret.Data.PutTime),
+		ContentType:  ret.Data.MimeType,
+		ETag:         ret.Data.Hash,
+	}, nil
+}
+
+// List 列举文件
+// prefix 为目录前缀，非递归时以 / 作为目录分隔符，子目录以 IsDir 的形式返回
+// 游标即七牛云的 marker，Limit 小于等于0或大于1000时按1000处理
+func (qn *QiniuFilesystem) List(ctx context.Context, prefix string, opts types.ListOptions) (types.ListResult, error) {
+	prefix = strings.TrimLeft(prefix, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	delimiter := "/"
+	if opts.Recursive {
+		delimiter = ""
+	}
+
+	limit := opts.Limit
+	if limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
+
+	ret, hasNext, err := qn.bucketManager.ListFilesWithContext(ctx, qn.Bucket.Name,
+		storage.ListInputOptionsPrefix(prefix),
+		storage.ListInputOptionsDelimiter(delimiter),
+		storage.ListInputOptionsMarker(opts.Cursor),
+		storage.ListInputOptionsLimit(limit),
+	)
+	if err != nil {
+		return types.ListResult{}, convertError("list", prefix, err)
+	}
+
+	result := types.ListResult{}
+	for _, dir := range ret.CommonPrefixes {
+		result.Files = append(result.Files, types.FileInfo{Path: dir, IsDir: true})
+	}
+	for _, item := range ret.Items {
+		result.Files = append(result.Files, types.FileInfo{
+			Path:         item.Key,
+			Size:         item.Fsize,
+			LastModified: storage.ParsePutTime(item.PutTime),
+			ContentType:  item.MimeType,
+			ETag:         item.Hash,
+		})
+	}
+	if hasNext {
+		result.NextCursor = ret.Marker
+	}
+	return result, nil
+}
+
+// Copy 复制文件 使用七牛云的服务端复制
+func (qn *QiniuFilesystem) Copy(ctx context.Context, src, dst string, overwrite bool) error {
+	_, err := qn.batchOne(ctx, storage.URICopy(qn.Bucket.Name, src, qn.Bucket.Name, dst, overwrite))
+	return convertCopyMoveError("copy", src, dst, err)
+}
+
+// Move 移动文件 使用七牛云的服务端移动
+func (qn *QiniuFilesystem) Move(ctx context.Context, src, dst string, overwrite bool) error {
+	_, err := qn.batchOne(ctx, storage.URIMove(qn.Bucket.Name, src, qn.Bucket.Name, dst, overwrite))
+	return convertCopyMoveError("move", src, dst, err)
+}
+
+// convertCopyMoveError 目标文件已存在的错误归属于目标文件，其余错误归属于源文件
+func convertCopyMoveError(op, src, dst string, err error) error {
+	var errInfo *storage.ErrorInfo
+	if errors.As(err, &errInfo) && errInfo.Code == 614 {
+		return types.NewPathError(op, dst, types.ErrAlreadyExists, err)
+	}
+	return convertError(op, src, err)
 }
 
 type ZipOptions struct {
@@ -287,6 +448,12 @@ type ZipOptions struct {
 // mkzipArgs: 打包参数
 // saveAs: 保存参数
 func (qn *QiniuFilesystem) Zip(mkzipArgs *MkZipArgs, opts *ZipOptions) (string, error) {
+	return qn.ZipWithContext(context.Background(), mkzipArgs, opts)
+}
+
+// ZipWithContext 打包资源
+// ctx 取消后停止上传索引文件和等待打包结果，已提交的打包任务不会被取消
+func (qn *QiniuFilesystem) ZipWithContext(ctx context.Context, mkzipArgs *MkZipArgs, opts *ZipOptions) (string, error) {
 	mkzipArgsStr, err := mkzipArgs.ToString()
 	if err != nil {
 		return "", fmt.Errorf("failed to get fop string, %w", err)
@@ -301,11 +468,15 @@ func (qn *QiniuFilesystem) Zip(mkzipArgs *MkZipArgs, opts *ZipOptions) (string,
 	force := true
 	fops := mkzipArgsStr
 
-	if !qn.Exists(key) {
+	exists, err := qn.ExistsE(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to check index file, %w", err)
+	}
+	if !exists {
 		indexContents := []byte(mkzipArgs.GetUrlsStr())
 
 		// 如果打包索引文件不存在，则先上传
-		err := qn.Put(context.Background(), key, indexContents)
+		err := qn.Put(ctx, key, indexContents)
 		if err != nil {
 			return "", fmt.Errorf("failed to put index file, %w", err)
 		}
@@ -336,6 +507,10 @@ func (qn *QiniuFilesystem) Zip(mkzipArgs *MkZipArgs, opts *ZipOptions) (string,
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
 	// client.DebugMode = true
 	// client.DeepDebugInfo = true
 	persistentID, err := qn.operationManager.Pfop(
@@ -352,10 +527,14 @@ func (qn *QiniuFilesystem) Zip(mkzipArgs *MkZipArgs, opts *ZipOptions) (string,
 	}
 
 	// 等待完成
-	if opts.IsWait {
+	if opts != nil && opts.IsWait {
 		for {
 			// 等待500ms
-			time.Sleep(500 * time.Millisecond)
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(500 * time.Millisecond):
+			}
 			ret, err := qn.Prefop(persistentID)
 			if err != nil {
 				return "", fmt.Errorf("failed to prefop, %w", err)
@@ -384,6 +563,39 @@ func (qn *QiniuFilesystem) Prefop(persistentID string) (storage.PrefopRet, error
 	return ret, err
 }
 
+// convertError 将七牛云SDK返回的错误转换为通用错误
+// 612: 文件不存在 614: 文件已存在 401/403: 没有权限
+func convertError(op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var errInfo *storage.ErrorInfo
+	if errors.As(err, &errInfo) {
+		switch errInfo.Code {
+		case 612:
+			return types.NewPathError(op, path, types.ErrNotFound, err)
+		case 614:
+			return types.NewPathError(op, path, types.ErrAlreadyExists, err)
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return types.NewPathError(op, path, types.ErrPermission, err)
+		}
+	}
+	return types.NewPathError(op, path, nil, err)
+}
+
+// convertStatusCode 将下载请求的HTTP状态码转换为通用错误
+func convertStatusCode(op, path string, statusCode int) error {
+	err := fmt.Errorf("status code: %d", statusCode)
+	switch statusCode {
+	case http.StatusNotFound:
+		return types.NewPathError(op, path, types.ErrNotFound, err)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return types.NewPathError(op, path, types.ErrPermission, err)
+	}
+	return types.NewPathError(op, path, nil, err)
+}
+
 // removeQuerySignParams 移除查询参数中的签名参数
 func removeQuerySignParams(qs string) string {
 	if qs == "" {
@@ -62,6 +62,28 @@ func TestQiniuFilesystem_Put(t *testing.T) {
 	t.Log("upload success, remote url:", qnFs.Bucket.Domain+"/"+remoteKey)
 }
 
+func TestQiniuFilesystem_PutStreamAndGetStream(t *testing.T) {
+	uploadData := []byte("流式上传测试文件")
+	remoteKey := filesystem.BuildUploadKey("test_stream", "txt")
+	err := qnFs.PutStream(context.Background(), remoteKey, strings.NewReader(string(uploadData)), int64(len(uploadData)))
+	if err != nil {
+		t.Fatalf("PutStream failed: %v", err)
+	}
+	defer qnFs.Delete(remoteKey)
+
+	reader, err := qnFs.GetStream(context.Background(), remoteKey)
+	if err != nil {
+		t.Fatalf("GetStream failed: %v", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Read stream failed: %v", err)
+	}
+	assert.Equal(t, uploadData, data)
+}
+
 func TestQiniuFilesystem_Get(t *testing.T) {
 	remoteUrl := os.Getenv("QINIU_SECURE_TEST_REMOTE_KEY")
 	data, err := qnFs.Get(remoteUrl)
@@ -318,6 +340,9 @@ func TestQiniuFilesystem_Delete(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error when getting deleted file, got nil")
 	}
+
+	_, err = qnFs.Stat(context.Background(), remoteKey)
+	assert.ErrorIs(t, err, filesystem.ErrNotFound)
 }
 
 func TestCensor_CheckImageByURI(t *testing.T) {
@@ -419,6 +444,80 @@ func TestQiniuFilesystem_Exists(t *testing.T) {
 	if qnFsPrivate.Exists(testFile) {
 		t.Error("Expected file to not exist, but it does")
 	}
+
+	exists, err := qnFsPrivate.ExistsE(context.Background(), testFile)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestQiniuFilesystem_Stat(t *testing.T) {
+	testData := []byte("test file for stat")
+	remoteKey := filesystem.BuildUploadKey("test_stat", "txt")
+	err := qnFsPrivate.Put(context.Background(), remoteKey, testData)
+	if err != nil {
+		t.Fatalf("Failed to upload test file: %v", err)
+	}
+	defer qnFsPrivate.Delete(remoteKey)
+
+	info, err := qnFsPrivate.Stat(context.Background(), remoteKey)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+
+	assert.Equal(t, int64(len(testData)), info.Size)
+	assert.NotEmpty(t, info.ETag)
+	assert.NotEmpty(t, info.ContentType)
+	assert.False(t, info.LastModified.IsZero())
+}
+
+func TestQiniuFilesystem_List(t *testing.T) {
+	dir := fmt.Sprintf("test_list_%d", time.Now().UnixNano())
+	keys := []string{dir + "/a.txt", dir + "/b.txt", dir + "/sub/c.txt"}
+	for _, key := range keys {
+		err := qnFsPrivate.Put(context.Background(), key, []byte(key))
+		if err != nil {
+			t.Fatalf("Failed to upload test file: %v", err)
+		}
+		defer qnFsPrivate.Delete(key)
+	}
+
+	result, err := qnFsPrivate.List(context.Background(), dir, filesystem.ListOptions{})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	assert.Len(t, result.Files, 3)
+
+	var walked []string
+	err = filesystem.Walk(context.Background(), qnFsPrivate, dir, func(info filesystem.FileInfo) error {
+		walked = append(walked, info.Path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	assert.ElementsMatch(t, keys, walked)
+}
+
+func TestQiniuFilesystem_CopyAndMove(t *testing.T) {
+	srcKey := filesystem.BuildUploadKey("test_copy", "txt")
+	dstKey := filesystem.BuildUploadKey("test_copy", "txt")
+	movedKey := filesystem.BuildUploadKey("test_move", "txt")
+	err := qnFsPrivate.Put(context.Background(), srcKey, []byte("copy and move"))
+	if err != nil {
+		t.Fatalf("Failed to upload test file: %v", err)
+	}
+	defer qnFsPrivate.Delete(dstKey)
+	defer qnFsPrivate.Delete(movedKey)
+
+	err = qnFsPrivate.Copy(context.Background(), srcKey, dstKey, false)
+	assert.NoError(t, err)
+
+	err = qnFsPrivate.Copy(context.Background(), srcKey, dstKey, false)
+	assert.ErrorIs(t, err, filesystem.ErrAlreadyExists)
+
+	err = qnFsPrivate.Move(context.Background(), srcKey, movedKey, false)
+	assert.NoError(t, err)
+	assert.False(t, qnFsPrivate.Exists(srcKey))
 }
 
 func TestQiniuFilesystem_Zip(t *testing.T) {
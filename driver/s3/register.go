@@ -0,0 +1,24 @@
+package s3
+
+import (
+	filesystem "github.com/yu1ec/go-filesystem"
+	"github.com/yu1ec/go-filesystem/config"
+)
+
+func init() {
+	filesystem.RegisterDriver("s3", func(cfg any) (filesystem.Filesystem, error) {
+		var c config.S3DriverConfig
+		if err := filesystem.DecodeConfig(cfg, &c); err != nil {
+			return nil, err
+		}
+		bucket := Bucket{
+			Name:         c.Bucket,
+			Region:       c.Region,
+			Endpoint:     c.Endpoint,
+			UsePathStyle: c.UsePathStyle,
+			Domain:       c.Domain,
+			PartSize:     c.PartSize,
+		}
+		return NewStorage(c.AccessKey, c.SecretKey, bucket), nil
+	})
+}
@@ -0,0 +1,444 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/yu1ec/go-filesystem/internal/objstore"
+	"github.com/yu1ec/go-filesystem/types"
+)
+
+// DefaultPartSize 默认分片大小，S3 要求除最后一片外每片不小于 5MB
+const DefaultPartSize = objstore.DefaultPartSize
+
+type S3Filesystem struct {
+	AccessKey string
+	SecretKey string
+	Bucket    Bucket
+
+	client  *awss3.Client
+	presign *awss3.PresignClient
+}
+
+// Bucket 存储桶
+type Bucket struct {
+	Name         string // 存储桶名称
+	Region       string // 区域 为空时使用 us-east-1
+	Endpoint     string // 服务地址 使用 MinIO、Ceph 等兼容服务时设置，为空时使用 AWS
+	UsePathStyle bool   // 是否使用路径形式访问存储桶 兼容服务通常需要开启
+	Domain       string // 访问域名 如CDN域名，为空时根据 Endpoint 生成
+	PartSize     int64  // 分片大小 超过此大小的文件使用分片上传，小于等于0时使用 DefaultPartSize
+}
+
+// NewStorage 创建S3存储
+func NewStorage(accessKey, secretKey string, bucket Bucket) *S3Filesystem {
+	if bucket.Region == "" {
+		bucket.Region = "us-east-1"
+	}
+
+	s3Fs := &S3Filesystem{
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		Bucket:    bucket,
+	}
+
+	opts := awss3.Options{
+		Region:       bucket.Region,
+		Credentials:  aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")),
+		UsePathStyle: bucket.UsePathStyle,
+		// 兼容服务大多不支持新版SDK默认附加的校验和，只在接口要求时计算
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		ResponseChecksumValidation: aws.ResponseChecksumValidationWhenRequired,
+	}
+	if bucket.Endpoint != "" {
+		opts.BaseEndpoint = aws.String(bucket.Endpoint)
+	}
+
+	s3Fs.client = awss3.New(opts)
+	s3Fs.presign = awss3.NewPresignClient(s3Fs.client)
+	return s3Fs
+}
+
+func (fs *S3Filesystem) Put(ctx context.Context, path string, data []byte) error {
+	return fs.PutStream(ctx, path, bytes.NewReader(data), int64(len(data)))
+}
+
+func (fs *S3Filesystem) PutWithoutContext(path string, data []byte) error {
+	return fs.Put(context.Background(), path, data)
+}
+
+// PutStream 以流的方式写入文件
+// 数据不超过分片大小时直接上传，否则使用分片上传，内存占用不超过一个分片
+func (fs *S3Filesystem) PutStream(ctx context.Context, path string, reader io.Reader, size int64) error {
+	return convertError("put", path, objstore.PutStream(ctx, uploader{fs}, objstore.Key(path), reader, size, fs.Bucket.PartSize))
+}
+
+// uploader 实现 objstore.Uploader
+type uploader struct {
+	fs *S3Filesystem
+}
+
+func (u uploader) PutObject(ctx context.Context, key, contentType string, data []byte) error {
+	_, err := u.fs.client.PutObject(ctx, &awss3.PutObjectInput{
+		Bucket:        aws.String(u.fs.Bucket.Name),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+		ContentType:   aws.String(contentType),
+	})
+	return err
+}
+
+func (u uploader) CreateMultipart(ctx context.Context, key, contentType string) (objstore.Multipart, error) {
+	created, err := u.fs.client.CreateMultipartUpload(ctx, &awss3.CreateMultipartUploadInput{
+		Bucket:      aws.String(u.fs.Bucket.Name),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &multipart{fs: u.fs, key: key, uploadId: created.UploadId}, nil
+}
+
+// multipart 进行中的分片上传
+type multipart struct {
+	fs       *S3Filesystem
+	key      string
+	uploadId *string
+	parts    []s3types.CompletedPart
+}
+
+func (m *multipart) UploadPart(ctx context.Context, partNumber int, data []byte) error {
+	out, err := m.fs.client.UploadPart(ctx, &awss3.UploadPartInput{
+		Bucket:        aws.String(m.fs.Bucket.Name),
+		Key:           aws.String(m.key),
+		UploadId:      m.uploadId,
+		PartNumber:    aws.Int32(int32(partNumber)),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+	})
+	if err != nil {
+		return err
+	}
+	m.parts = append(m.parts, s3types.CompletedPart{
+		ETag:       out.ETag,
+		PartNumber: aws.Int32(int32(partNumber)),
+	})
+	return nil
+}
+
+func (m *multipart) Complete(ctx context.Context) error {
+	_, err := m.fs.client.CompleteMultipartUpload(ctx, &awss3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(m.fs.Bucket.Name),
+		Key:             aws.String(m.key),
+		UploadId:        m.uploadId,
+		MultipartUpload: &s3types.CompletedMultipartUpload{Parts: m.parts},
+	})
+	return err
+}
+
+func (m *multipart) Abort(ctx context.Context) error {
+	_, err := m.fs.client.AbortMultipartUpload(ctx, &awss3.AbortMultipartUploadInput{
+		Bucket:   aws.String(m.fs.Bucket.Name),
+		Key:      aws.String(m.key),
+		UploadId: m.uploadId,
+	})
+	return err
+}
+
+func (fs *S3Filesystem) Get(path string) ([]byte, error) {
+	return fs.GetWithContext(context.Background(), path)
+}
+
+// GetWithContext 获取文件内容
+func (fs *S3Filesystem) GetWithContext(ctx context.Context, path string) ([]byte, error) {
+	body, err := fs.GetStream(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, types.NewPathError("get", path, nil, err)
+	}
+	return data, nil
+}
+
+// GetStream 以流的方式读取文件
+func (fs *S3Filesystem) GetStream(ctx context.Context, path string) (io.ReadCloser, error) {
+	out, err := fs.client.GetObject(ctx, &awss3.GetObjectInput{
+		Bucket: aws.String(fs.Bucket.Name),
+		Key:    aws.String(objstore.Key(path)),
+	})
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+	return out.Body, nil
+}
+
+// GetStreamRange 从 offset 开始读取 length 字节，length 小于等于0时读取到文件末尾
+func (fs *S3Filesystem) GetStreamRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	out, err := fs.client.GetObject(ctx, &awss3.GetObjectInput{
+		Bucket: aws.String(fs.Bucket.Name),
+		Key:    aws.String(objstore.Key(path)),
+		Range:  aws.String(objstore.RangeHeader(offset, length)),
+	})
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+	return out.Body, nil
+}
+
+// GetUrl 获取文件的URL
+// 未设置 Domain 时根据 Endpoint 和访问形式生成
+func (fs *S3Filesystem) GetUrl(path string) string {
+	key := objstore.EscapeKey(objstore.Key(path))
+	if fs.Bucket.Domain != "" {
+		return strings.TrimRight(fs.Bucket.Domain, "/") + "/" + key
+	}
+
+	endpoint := fs.Bucket.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + fs.Bucket.Region + ".amazonaws.com"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return strings.TrimRight(endpoint, "/") + "/" + fs.Bucket.Name + "/" + key
+	}
+	basePath := strings.TrimRight(u.Path, "/")
+	if fs.Bucket.UsePathStyle {
+		return u.Scheme + "://" + u.Host + basePath + "/" + fs.Bucket.Name + "/" + key
+	}
+	return u.Scheme + "://" + fs.Bucket.Name + "." + u.Host + basePath + "/" + key
+}
+
+// GetSignedUrl 获取预签名URL
+// path: 文件路径
+// expires: 过期时间 单位/秒
+func (fs *S3Filesystem) GetSignedUrl(path string, expires int64) (string, error) {
+	req, err := fs.presign.PresignGetObject(context.Background(), &awss3.GetObjectInput{
+		Bucket: aws.String(fs.Bucket.Name),
+		Key:    aws.String(objstore.Key(path)),
+	}, awss3.WithPresignExpires(time.Duration(expires)*time.Second))
+	if err != nil {
+		return "", err
+	}
+	return req.URL, nil
+}
+
+// MustGetSignedUrl 获取签名URL
+func (fs *S3Filesystem) MustGetSignedUrl(path string, expires int64) string {
+	url, err := fs.GetSignedUrl(path, expires)
+	if err != nil {
+		panic(err)
+	}
+	return url
+}
+
+func (fs *S3Filesystem) GetImageWidthHeight(path string) (int, int, error) {
+	return fs.GetImageWidthHeightWithContext(context.Background(), path)
+}
+
+// GetImageWidthHeightWithContext 获取图片的宽高 只读取图片头部信息
+func (fs *S3Filesystem) GetImageWidthHeightWithContext(ctx context.Context, path string) (int, int, error) {
+	body, err := fs.GetStream(ctx, path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer body.Close()
+
+	cfg, _, err := image.DecodeConfig(body)
+	if err != nil {
+		return 0, 0, err
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// Stat 获取文件信息 通过HEAD请求获取
+func (fs *S3Filesystem) Stat(ctx context.Context, path string) (types.FileInfo, error) {
+	out, err := fs.client.HeadObject(ctx, &awss3.HeadObjectInput{
+		Bucket: aws.String(fs.Bucket.Name),
+		Key:    aws.String(objstore.Key(path)),
+	})
+	if err != nil {
+		return types.FileInfo{}, convertError("stat", path, err)
+	}
+
+	return types.FileInfo{
+		Path:         path,
+		Size:         aws.ToInt64(out.ContentLength),
+		LastModified: aws.ToTime(out.LastModified),
+		ContentType:  aws.ToString(out.ContentType),
+		ETag:         aws.ToString(out.ETag),
+	}, nil
+}
+
+// List 列举目录下的文件
+// 使用 ListObjectsV2，游标为S3返回的 ContinuationToken
+func (fs *S3Filesystem) List(ctx context.Context, prefix string, opts types.ListOptions) (types.ListResult, error) {
+	prefix = objstore.Key(prefix)
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	input := &awss3.ListObjectsV2Input{
+		Bucket: aws.String(fs.Bucket.Name),
+		Prefix: aws.String(prefix),
+	}
+	if !opts.Recursive {
+		input.Delimiter = aws.String("/")
+	}
+	if opts.Limit > 0 && opts.Limit < 1000 {
+		input.MaxKeys = aws.Int32(int32(opts.Limit))
+	}
+	if opts.Cursor != "" {
+		input.ContinuationToken = aws.String(opts.Cursor)
+	}
+
+	out, err := fs.client.ListObjectsV2(ctx, input)
+	if err != nil {
+		return types.ListResult{}, convertError("list", prefix, err)
+	}
+
+	result := types.ListResult{}
+	for _, dir := range out.CommonPrefixes {
+		result.Files = append(result.Files, types.FileInfo{Path: aws.ToString(dir.Prefix), IsDir: true})
+	}
+	for _, item := range out.Contents {
+		result.Files = append(result.Files, types.FileInfo{
+			Path:         aws.ToString(item.Key),
+			Size:         aws.ToInt64(item.Size),
+			LastModified: aws.ToTime(item.LastModified),
+			ETag:         aws.ToString(item.ETag),
+		})
+	}
+	if aws.ToBool(out.IsTruncated) {
+		result.NextCursor = aws.ToString(out.NextContinuationToken)
+	}
+	return result, nil
+}
+
+// Copy 复制文件 使用S3的服务端复制，单次最大支持 5GB
+func (fs *S3Filesystem) Copy(ctx context.Context, src, dst string, overwrite bool) error {
+	return fs.copyObject(ctx, "copy", src, dst, overwrite)
+}
+
+// Move 移动文件 S3不支持移动，使用服务端复制后删除源文件
+func (fs *S3Filesystem) Move(ctx context.Context, src, dst string, overwrite bool) error {
+	if err := fs.copyObject(ctx, "move", src, dst, overwrite); err != nil {
+		return err
+	}
+	return convertError("move", src, fs.deleteObject(ctx, src))
+}
+
+func (fs *S3Filesystem) copyObject(ctx context.Context, op, src, dst string, overwrite bool) error {
+	if !overwrite {
+		exists, err := fs.ExistsE(ctx, dst)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return types.NewPathError(op, dst, types.ErrAlreadyExists, nil)
+		}
+	}
+
+	_, err := fs.client.CopyObject(ctx, &awss3.CopyObjectInput{
+		Bucket:     aws.String(fs.Bucket.Name),
+		Key:        aws.String(objstore.Key(dst)),
+		CopySource: aws.String(fs.Bucket.Name + "/" + objstore.EscapeKey(objstore.Key(src))),
+	})
+	return convertError(op, src, err)
+}
+
+// Delete 删除文件
+func (fs *S3Filesystem) Delete(path string) error {
+	return fs.DeleteWithContext(context.Background(), path)
+}
+
+// DeleteWithContext 删除文件
+// S3删除不存在的文件不会返回错误，因此先通过 HEAD 确认文件存在
+func (fs *S3Filesystem) DeleteWithContext(ctx context.Context, path string) error {
+	_, err := fs.client.HeadObject(ctx, &awss3.HeadObjectInput{
+		Bucket: aws.String(fs.Bucket.Name),
+		Key:    aws.String(objstore.Key(path)),
+	})
+	if err != nil {
+		return convertError("delete", path, err)
+	}
+	return convertError("delete", path, fs.deleteObject(ctx, path))
+}
+
+func (fs *S3Filesystem) deleteObject(ctx context.Context, path string) error {
+	_, err := fs.client.DeleteObject(ctx, &awss3.DeleteObjectInput{
+		Bucket: aws.String(fs.Bucket.Name),
+		Key:    aws.String(objstore.Key(path)),
+	})
+	return err
+}
+
+// Exists 判断文件是否存在
+func (fs *S3Filesystem) Exists(path string) bool {
+	return fs.ExistsWithContext(context.Background(), path)
+}
+
+// ExistsWithContext 判断文件是否存在 无法判断时返回 false，需要区分时使用 ExistsE
+func (fs *S3Filesystem) ExistsWithContext(ctx context.Context, path string) bool {
+	exists, _ := fs.ExistsE(ctx, path)
+	return exists
+}
+
+// ExistsE 判断文件是否存在
+// 文件不存在时返回 false 和 nil，认证失败、网络错误等无法判断的情况返回错误
+func (fs *S3Filesystem) ExistsE(ctx context.Context, path string) (bool, error) {
+	_, err := fs.Stat(ctx, path)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, types.ErrNotFound) {
+		return false, nil
+	}
+	return false, err
+}
+
+// convertError 将S3返回的错误转换为通用错误
+func convertError(op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "NoSuchKey", "NotFound":
+			return types.NewPathError(op, path, types.ErrNotFound, err)
+		case "AccessDenied", "Forbidden", "InvalidAccessKeyId", "SignatureDoesNotMatch":
+			return types.NewPathError(op, path, types.ErrPermission, err)
+		}
+	}
+
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		switch respErr.HTTPStatusCode() {
+		case http.StatusNotFound:
+			return types.NewPathError(op, path, types.ErrNotFound, err)
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return types.NewPathError(op, path, types.ErrPermission, err)
+		}
+	}
+	return types.NewPathError(op, path, nil, err)
+}
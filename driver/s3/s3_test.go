@@ -0,0 +1,247 @@
+package s3_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yu1ec/go-filesystem/driver/s3"
+	"github.com/yu1ec/go-filesystem/internal/drivertest"
+)
+
+const testBucket = "test-bucket"
+
+// fakeS3 简单的S3服务，只实现驱动用到的接口，使用路径形式访问存储桶
+type fakeS3 struct {
+	mu         sync.Mutex
+	objects    drivertest.Objects
+	uploads    map[string]map[int][]byte
+	multiparts int // 完成的分片上传次数
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{
+		objects: make(drivertest.Objects),
+		uploads: make(map[string]map[int][]byte),
+	}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != testBucket {
+		drivertest.WriteXMLError(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	q := r.URL.Query()
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		f.list(w, q)
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		id := strconv.Itoa(len(f.uploads) + 1)
+		f.uploads[id] = make(map[int][]byte)
+		drivertest.WriteXML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: bucket, Key: key, UploadId: id})
+	case r.Method == http.MethodPut && q.Has("uploadId"):
+		parts, ok := f.uploads[q.Get("uploadId")]
+		if !ok {
+			drivertest.WriteXMLError(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		partNumber, _ := strconv.Atoi(q.Get("partNumber"))
+		parts[partNumber] = data
+		w.Header().Set("ETag", drivertest.Object{Data: data}.ETag())
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		parts, ok := f.uploads[q.Get("uploadId")]
+		if !ok {
+			drivertest.WriteXMLError(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		var complete struct {
+			Parts []struct{ PartNumber int } `xml:"Part"`
+		}
+		xml.NewDecoder(r.Body).Decode(&complete)
+		var data []byte
+		for _, part := range complete.Parts {
+			data = append(data, parts[part.PartNumber]...)
+		}
+		delete(f.uploads, q.Get("uploadId"))
+		obj := drivertest.NewObject(data, "application/octet-stream")
+		f.objects[key] = obj
+		f.multiparts++
+		drivertest.WriteXML(w, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Key     string
+			ETag    string
+		}{Key: key, ETag: obj.ETag()})
+	case r.Method == http.MethodDelete && q.Has("uploadId"):
+		delete(f.uploads, q.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		source, _ := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+		_, srcKey, _ := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+		obj, ok := f.objects[srcKey]
+		if !ok {
+			drivertest.WriteXMLError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		obj.ModTime = time.Now()
+		f.objects[key] = obj
+		drivertest.WriteXML(w, struct {
+			XMLName xml.Name `xml:"CopyObjectResult"`
+			ETag    string
+		}{ETag: obj.ETag()})
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		obj := drivertest.NewObject(data, r.Header.Get("Content-Type"))
+		f.objects[key] = obj
+		w.Header().Set("ETag", obj.ETag())
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		obj, ok := f.objects[key]
+		if !ok {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			drivertest.WriteXMLError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		obj.Serve(w, r)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		drivertest.WriteXMLError(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+// list 实现 ListObjectsV2，游标为上一页最后一个key或公共前缀
+func (f *fakeS3) list(w http.ResponseWriter, q url.Values) {
+	maxKeys, err := strconv.Atoi(q.Get("max-keys"))
+	if err != nil {
+		maxKeys = 1000
+	}
+	keys, prefixes, next := f.objects.List(q.Get("prefix"), q.Get("delimiter"), q.Get("continuation-token"), maxKeys)
+
+	type content struct {
+		Key          string
+		LastModified string
+		ETag         string
+		Size         int
+	}
+	type commonPrefix struct{ Prefix string }
+	result := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Contents              []content
+		CommonPrefixes        []commonPrefix
+		IsTruncated           bool
+		NextContinuationToken string `xml:",omitempty"`
+	}{IsTruncated: next != "", NextContinuationToken: next}
+	for _, prefix := range prefixes {
+		result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{prefix})
+	}
+	for _, key := range keys {
+		obj := f.objects[key]
+		result.Contents = append(result.Contents, content{
+			Key:          key,
+			LastModified: obj.ModTime.UTC().Format("2006-01-02T15:04:05.000Z"),
+			ETag:         obj.ETag(),
+			Size:         len(obj.Data),
+		})
+	}
+	drivertest.WriteXML(w, result)
+}
+
+func setupTestServer(t *testing.T) (*s3.S3Filesystem, *fakeS3) {
+	fake := newFakeS3()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	fs := s3.NewStorage("testkey", "testsecret", s3.Bucket{
+		Name:         testBucket,
+		Endpoint:     server.URL,
+		UsePathStyle: true,
+		PartSize:     1024,
+	})
+	return fs, fake
+}
+
+func TestS3Filesystem(t *testing.T) {
+	fs, fake := setupTestServer(t)
+	drivertest.Run(t, fs)
+
+	t.Run("分片上传", func(t *testing.T) {
+		multiparts := fake.multiparts
+		data := bytes.Repeat([]byte("0123456789"), 350)
+		if err := fs.PutStream(context.Background(), "large.bin", bytes.NewReader(data), int64(len(data))); err != nil {
+			t.Fatalf("PutStream失败：%v", err)
+		}
+		if fake.multiparts != multiparts+1 {
+			t.Errorf("超过分片大小时应使用分片上传")
+		}
+		if retrieved, _ := fs.Get("large.bin"); !bytes.Equal(retrieved, data) {
+			t.Errorf("分片上传的数据不匹配，长度：%d", len(retrieved))
+		}
+	})
+}
+
+func TestS3Filesystem_GetUrl(t *testing.T) {
+	fs, _ := setupTestServer(t)
+
+	url := fs.GetUrl("dir/a b.txt")
+	if !strings.HasSuffix(url, "/"+testBucket+"/dir/a%20b.txt") {
+		t.Errorf("URL不正确：%s", url)
+	}
+
+	cdn := s3.NewStorage("k", "s", s3.Bucket{Name: "b", Domain: "https://cdn.example.com/"})
+	if url := cdn.GetUrl("/a.txt"); url != "https://cdn.example.com/a.txt" {
+		t.Errorf("URL不正确：%s", url)
+	}
+
+	aws := s3.NewStorage("k", "s", s3.Bucket{Name: "b", Region: "eu-west-1"})
+	if url := aws.GetUrl("a.txt"); url != "https://b.s3.eu-west-1.amazonaws.com/a.txt" {
+		t.Errorf("URL不正确：%s", url)
+	}
+}
+
+func TestS3Filesystem_GetSignedUrl(t *testing.T) {
+	fs, _ := setupTestServer(t)
+	if err := fs.Put(context.Background(), "test.txt", []byte("测试数据")); err != nil {
+		t.Fatalf("Put失败：%v", err)
+	}
+
+	signedUrl, err := fs.GetSignedUrl("test.txt", 600)
+	if err != nil {
+		t.Fatalf("GetSignedUrl失败：%v", err)
+	}
+	if !strings.Contains(signedUrl, "X-Amz-Expires=600") || !strings.Contains(signedUrl, "X-Amz-Signature=") {
+		t.Errorf("签名URL不正确：%s", signedUrl)
+	}
+
+	resp, err := http.Get(signedUrl)
+	if err != nil {
+		t.Fatalf("请求签名URL失败：%v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "测试数据" {
+		t.Errorf("签名URL获取的数据不匹配：%s", string(body))
+	}
+}
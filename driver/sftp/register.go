@@ -0,0 +1,30 @@
+package sftp
+
+import (
+	filesystem "github.com/yu1ec/go-filesystem"
+	"github.com/yu1ec/go-filesystem/config"
+)
+
+func init() {
+	filesystem.RegisterDriver("sftp", func(cfg any) (filesystem.Filesystem, error) {
+		var c config.SftpDriverConfig
+		if err := filesystem.DecodeConfig(cfg, &c); err != nil {
+			return nil, err
+		}
+		fs, err := NewStorage(Server{
+			Host:                  c.Host,
+			Port:                  c.Port,
+			Username:              c.Username,
+			Password:              c.Password,
+			PrivateKey:            c.PrivateKey,
+			Passphrase:            c.Passphrase,
+			HostKey:               c.HostKey,
+			InsecureIgnoreHostKey: c.InsecureIgnoreHostKey,
+			Root:                  c.Root,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return fs, nil
+	})
+}
@@ -0,0 +1,566 @@
+package sftp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"net"
+	"net/url"
+	"os"
+	pathpkg "path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/yu1ec/go-filesystem/internal/fsutil"
+	"github.com/yu1ec/go-filesystem/types"
+	"golang.org/x/crypto/ssh"
+)
+
+// DefaultTimeout 默认连接超时时间
+const DefaultTimeout = 30 * time.Second
+
+type SftpFilesystem struct {
+	Server Server
+
+	config *ssh.ClientConfig
+
+	mu     sync.Mutex
+	conn   *ssh.Client
+	client *sftp.Client
+}
+
+// Server SFTP服务器
+type Server struct {
+	Host       string // 主机
+	Port       int    // 端口 为0时使用22
+	Username   string // 用户名
+	Password   string // 密码
+	PrivateKey string // PEM格式私钥 与密码同时设置时优先使用私钥认证
+	Passphrase string // 私钥密码
+	// HostKey 服务器公钥 支持 authorized_keys 格式或 SHA256 指纹（如 SHA256:xxx）
+	// 为空时必须设置 InsecureIgnoreHostKey
+	HostKey               string
+	InsecureIgnoreHostKey bool          // 不校验服务器公钥 存在中间人攻击风险，仅用于测试
+	Root                  string        // 根目录 为空时使用登录后的默认目录
+	Timeout               time.Duration // 连接超时 小于等于0时使用 DefaultTimeout
+}
+
+// NewStorage 创建SFTP存储 创建时会连接服务器以校验认证信息
+func NewStorage(server Server) (*SftpFilesystem, error) {
+	config, err := clientConfig(server)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := &SftpFilesystem{Server: server, config: config}
+	if _, err := fs.sftpClient(context.Background()); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// clientConfig 根据服务器配置生成SSH客户端配置
+func clientConfig(server Server) (*ssh.ClientConfig, error) {
+	config := &ssh.ClientConfig{
+		User:    server.Username,
+		Timeout: server.Timeout,
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	if server.PrivateKey != "" {
+		var signer ssh.Signer
+		var err error
+		if server.Passphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(server.PrivateKey), []byte(server.Passphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey([]byte(server.PrivateKey))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %w", err)
+		}
+		config.Auth = append(config.Auth, ssh.PublicKeys(signer))
+	}
+	if server.Password != "" {
+		config.Auth = append(config.Auth, ssh.Password(server.Password))
+	}
+	if len(config.Auth) == 0 {
+		return nil, errors.New("password or private key is required")
+	}
+
+	switch {
+	case server.HostKey == "" && server.InsecureIgnoreHostKey:
+		config.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+	case server.HostKey == "":
+		return nil, errors.New("host key is required")
+	case strings.HasPrefix(server.HostKey, "SHA256:"):
+		config.HostKeyCallback = func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			if fingerprint := ssh.FingerprintSHA256(key); fingerprint != server.HostKey {
+				return fmt.Errorf("host key mismatch: got %s", fingerprint)
+			}
+			return nil
+		}
+	default:
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(server.HostKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid host key: %w", err)
+		}
+		config.HostKeyCallback = ssh.FixedHostKey(key)
+		// 服务器有多个公钥时，只协商与固定公钥相同的算法
+		config.HostKeyAlgorithms = []string{key.Type()}
+		if key.Type() == ssh.KeyAlgoRSA {
+			config.HostKeyAlgorithms = []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}
+		}
+	}
+	return config, nil
+}
+
+// sftpClient 获取SFTP客户端 连接断开后再次调用时重新连接
+// 连接时不持有锁，并发建立的多余连接会被关闭
+func (fs *SftpFilesystem) sftpClient(ctx context.Context) (*sftp.Client, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	fs.mu.Lock()
+	client := fs.client
+	fs.mu.Unlock()
+	if client != nil {
+		return client, nil
+	}
+
+	conn, client, err := fs.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fs.mu.Lock()
+	if fs.client != nil {
+		existing := fs.client
+		fs.mu.Unlock()
+		client.Close()
+		conn.Close()
+		return existing, nil
+	}
+	fs.conn, fs.client = conn, client
+	fs.mu.Unlock()
+
+	go func() {
+		client.Wait()
+		conn.Close()
+		fs.mu.Lock()
+		if fs.client == client {
+			fs.conn, fs.client = nil, nil
+		}
+		fs.mu.Unlock()
+	}()
+	return client, nil
+}
+
+// dial 连接服务器并创建SFTP客户端
+func (fs *SftpFilesystem) dial(ctx context.Context) (*ssh.Client, *sftp.Client, error) {
+	addr := fs.addr()
+	netConn, err := (&net.Dialer{Timeout: fs.config.Timeout}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	c, chans, reqs, err := ssh.NewClientConn(netConn, addr, fs.config)
+	if err != nil {
+		netConn.Close()
+		return nil, nil, err
+	}
+	conn := ssh.NewClient(c, chans, reqs)
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, client, nil
+}
+
+// Close 关闭与服务器的连接 关闭后再次调用其他方法时会重新连接
+func (fs *SftpFilesystem) Close() error {
+	fs.mu.Lock()
+	conn, client := fs.conn, fs.client
+	fs.conn, fs.client = nil, nil
+	fs.mu.Unlock()
+
+	if client == nil {
+		return nil
+	}
+	client.Close()
+	return conn.Close()
+}
+
+func (fs *SftpFilesystem) addr() string {
+	port := fs.Server.Port
+	if port == 0 {
+		port = 22
+	}
+	return net.JoinHostPort(fs.Server.Host, strconv.Itoa(port))
+}
+
+// fullPath 获取文件在服务器上的路径 路径不会超出根目录
+func (fs *SftpFilesystem) fullPath(path string) string {
+	return pathpkg.Join(fs.Server.Root, ".", pathpkg.Clean("/"+path))
+}
+
+func (fs *SftpFilesystem) Put(ctx context.Context, path string, data []byte) error {
+	return fs.PutStream(ctx, path, bytes.NewReader(data), int64(len(data)))
+}
+
+func (fs *SftpFilesystem) PutWithoutContext(path string, data []byte) error {
+	return fs.Put(context.Background(), path, data)
+}
+
+// PutStream 以流的方式写入文件 目录不存在时自动创建
+// 先写入同目录下的临时文件，完成后重命名为目标文件，读取方不会看到写了一半的文件
+func (fs *SftpFilesystem) PutStream(ctx context.Context, path string, reader io.Reader, size int64) (err error) {
+	client, err := fs.sftpClient(ctx)
+	if err != nil {
+		return types.NewPathError("put", path, nil, err)
+	}
+
+	fullPath := fs.fullPath(path)
+	if err := client.MkdirAll(pathpkg.Dir(fullPath)); err != nil {
+		return convertError("put", path, err)
+	}
+
+	tmpPath := tempName(fullPath, ".tmp")
+	f, err := client.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+	if err != nil {
+		return convertError("put", path, err)
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			client.Remove(tmpPath)
+		}
+	}()
+
+	if _, err = f.ReadFrom(fsutil.ContextReader(ctx, reader)); err != nil {
+		return convertError("put", path, err)
+	}
+	if err = f.Close(); err != nil {
+		return convertError("put", path, err)
+	}
+	if err = replace(client, tmpPath, fullPath); err != nil {
+		return convertError("put", path, err)
+	}
+	return nil
+}
+
+func (fs *SftpFilesystem) Get(path string) ([]byte, error) {
+	return fs.GetWithContext(context.Background(), path)
+}
+
+// GetWithContext 获取文件内容
+func (fs *SftpFilesystem) GetWithContext(ctx context.Context, path string) ([]byte, error) {
+	body, err := fs.GetStream(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(fsutil.ContextReader(ctx, body))
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+	return data, nil
+}
+
+// GetStream 以流的方式读取文件
+func (fs *SftpFilesystem) GetStream(ctx context.Context, path string) (io.ReadCloser, error) {
+	client, err := fs.sftpClient(ctx)
+	if err != nil {
+		return nil, types.NewPathError("get", path, nil, err)
+	}
+
+	f, err := client.Open(fs.fullPath(path))
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+	return f, nil
+}
+
+// GetUrl 获取文件的URL 格式为 sftp://user@host:port/path，不包含密码
+// 未设置根目录时路径相对于登录后的默认目录，使用 /~/ 表示
+func (fs *SftpFilesystem) GetUrl(path string) string {
+	fullPath := fs.fullPath(path)
+	if !strings.HasPrefix(fullPath, "/") {
+		fullPath = "/~/" + fullPath
+	}
+
+	host := fs.Server.Host
+	if fs.Server.Port != 0 && fs.Server.Port != 22 {
+		host = net.JoinHostPort(host, strconv.Itoa(fs.Server.Port))
+	}
+	u := url.URL{Scheme: "sftp", Host: host, Path: fullPath}
+	if fs.Server.Username != "" {
+		u.User = url.User(fs.Server.Username)
+	}
+	return u.String()
+}
+
+// GetSignedUrl SFTP不支持签名URL，返回与 GetUrl 相同的URL
+func (fs *SftpFilesystem) GetSignedUrl(path string, expires int64) (string, error) {
+	return fs.GetUrl(path), nil
+}
+
+// MustGetSignedUrl 获取签名URL
+func (fs *SftpFilesystem) MustGetSignedUrl(path string, expires int64) string {
+	url, err := fs.GetSignedUrl(path, expires)
+	if err != nil {
+		panic(err)
+	}
+	return url
+}
+
+func (fs *SftpFilesystem) GetImageWidthHeight(path string) (int, int, error) {
+	return fs.GetImageWidthHeightWithContext(context.Background(), path)
+}
+
+// GetImageWidthHeightWithContext 获取图片的宽高 只读取图片头部
+func (fs *SftpFilesystem) GetImageWidthHeightWithContext(ctx context.Context, path string) (int, int, error) {
+	body, err := fs.GetStream(ctx, path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer body.Close()
+
+	cfg, _, err := image.DecodeConfig(fsutil.ContextReader(ctx, body))
+	if err != nil {
+		return 0, 0, err
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// Stat 获取文件信息
+// ETag 由修改时间和文件大小生成，MIME类型仅根据扩展名判断
+func (fs *SftpFilesystem) Stat(ctx context.Context, path string) (types.FileInfo, error) {
+	client, err := fs.sftpClient(ctx)
+	if err != nil {
+		return types.FileInfo{}, types.NewPathError("stat", path, nil, err)
+	}
+
+	info, err := client.Stat(fs.fullPath(path))
+	if err != nil {
+		return types.FileInfo{}, convertError("stat", path, err)
+	}
+	return toFileInfo(path, info), nil
+}
+
+// List 列举目录下的文件
+// prefix 为目录路径，目录不存在时返回空列表
+func (fs *SftpFilesystem) List(ctx context.Context, prefix string, opts types.ListOptions) (types.ListResult, error) {
+	client, err := fs.sftpClient(ctx)
+	if err != nil {
+		return types.ListResult{}, types.NewPathError("list", prefix, nil, err)
+	}
+
+	var files []types.FileInfo
+	if err := fs.readDir(ctx, client, prefix, opts.Recursive, &files); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return types.ListResult{}, convertError("list", prefix, err)
+	}
+	return types.Paginate(files, opts.Cursor, opts.Limit), nil
+}
+
+// readDir 读取目录，递归时只收集文件
+func (fs *SftpFilesystem) readDir(ctx context.Context, client *sftp.Client, dir string, recursive bool, files *[]types.FileInfo) error {
+	infos, err := client.ReadDirContext(ctx, fs.fullPath(dir))
+	if err != nil {
+		return err
+	}
+
+	for _, info := range infos {
+		filePath := pathpkg.Join(dir, info.Name())
+		if info.IsDir() && recursive {
+			if err := fs.readDir(ctx, client, filePath, recursive, files); err != nil {
+				return err
+			}
+			continue
+		}
+		*files = append(*files, toFileInfo(filePath, info))
+	}
+	return nil
+}
+
+// toFileInfo 将 os.FileInfo 转换为通用的文件信息
+// 目录路径以 / 结尾
+func toFileInfo(path string, info os.FileInfo) types.FileInfo {
+	if info.IsDir() {
+		return types.FileInfo{
+			Path:         strings.TrimSuffix(path, "/") + "/",
+			LastModified: info.ModTime(),
+			IsDir:        true,
+		}
+	}
+
+	return types.FileInfo{
+		Path:         path,
+		Size:         info.Size(),
+		LastModified: info.ModTime(),
+		ContentType:  mime.TypeByExtension(pathpkg.Ext(path)),
+		ETag:         fsutil.ETag(info.ModTime(), info.Size()),
+	}
+}
+
+// Copy 复制文件 SFTP不支持服务端复制，通过读取源文件后写入目标文件实现
+func (fs *SftpFilesystem) Copy(ctx context.Context, src, dst string, overwrite bool) error {
+	if !overwrite {
+		exists, err := fs.ExistsE(ctx, dst)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return types.NewPathError("copy", dst, types.ErrAlreadyExists, nil)
+		}
+	}
+	if fs.fullPath(src) == fs.fullPath(dst) {
+		return nil
+	}
+
+	client, err := fs.sftpClient(ctx)
+	if err != nil {
+		return types.NewPathError("copy", src, nil, err)
+	}
+	f, err := client.Open(fs.fullPath(src))
+	if err != nil {
+		return convertError("copy", src, err)
+	}
+	defer f.Close()
+
+	return fs.PutStream(ctx, dst, f, -1)
+}
+
+// Move 移动文件
+func (fs *SftpFilesystem) Move(ctx context.Context, src, dst string, overwrite bool) error {
+	if !overwrite {
+		exists, err := fs.ExistsE(ctx, dst)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return types.NewPathError("move", dst, types.ErrAlreadyExists, nil)
+		}
+	}
+
+	client, err := fs.sftpClient(ctx)
+	if err != nil {
+		return types.NewPathError("move", src, nil, err)
+	}
+	srcPath, dstPath := fs.fullPath(src), fs.fullPath(dst)
+	if err := client.MkdirAll(pathpkg.Dir(dstPath)); err != nil {
+		return convertError("move", dst, err)
+	}
+
+	if overwrite {
+		return convertError("move", src, replace(client, srcPath, dstPath))
+	}
+	return convertError("move", src, client.Rename(srcPath, dstPath))
+}
+
+// replace 将 oldPath 重命名为 newPath，newPath 已存在时替换
+// 优先使用 posix-rename@openssh.com 扩展原子替换
+// 服务器不支持且 SFTP 重命名因目标存在而失败时，先将目标文件改名备份，移入后再删除备份，失败时恢复备份
+func replace(client *sftp.Client, oldPath, newPath string) error {
+	if _, ok := client.HasExtension("posix-rename@openssh.com"); ok {
+		return client.PosixRename(oldPath, newPath)
+	}
+
+	// 源文件不存在或无法访问时不改动目标文件
+	if _, err := client.Stat(oldPath); err != nil {
+		return err
+	}
+	err := client.Rename(oldPath, newPath)
+	if err == nil {
+		return nil
+	}
+	if _, statErr := client.Lstat(newPath); statErr != nil {
+		return err
+	}
+
+	backup := tempName(newPath, ".bak")
+	if err := client.Rename(newPath, backup); err != nil {
+		return err
+	}
+	if err := client.Rename(oldPath, newPath); err != nil {
+		client.Rename(backup, newPath)
+		return err
+	}
+	client.Remove(backup)
+	return nil
+}
+
+// tempName 生成与 path 同目录的隐藏临时文件名 .文件名.随机数+suffix
+func tempName(path, suffix string) string {
+	return pathpkg.Join(pathpkg.Dir(path), "."+pathpkg.Base(path)+"."+strconv.FormatUint(uint64(rand.Uint32()), 10)+suffix)
+}
+
+// Delete 删除文件
+func (fs *SftpFilesystem) Delete(path string) error {
+	return fs.DeleteWithContext(context.Background(), path)
+}
+
+// DeleteWithContext 删除文件
+func (fs *SftpFilesystem) DeleteWithContext(ctx context.Context, path string) error {
+	client, err := fs.sftpClient(ctx)
+	if err != nil {
+		return types.NewPathError("delete", path, nil, err)
+	}
+	return convertError("delete", path, client.Remove(fs.fullPath(path)))
+}
+
+// Exists 判断文件是否存在
+func (fs *SftpFilesystem) Exists(path string) bool {
+	return fs.ExistsWithContext(context.Background(), path)
+}
+
+// ExistsWithContext 判断文件是否存在 无法判断时返回 false，需要区分时使用 ExistsE
+func (fs *SftpFilesystem) ExistsWithContext(ctx context.Context, path string) bool {
+	exists, _ := fs.ExistsE(ctx, path)
+	return exists
+}
+
+// ExistsE 判断文件是否存在
+// 文件不存在时返回 false 和 nil，权限不足、网络错误等无法判断的情况返回错误
+func (fs *SftpFilesystem) ExistsE(ctx context.Context, path string) (bool, error) {
+	client, err := fs.sftpClient(ctx)
+	if err != nil {
+		return false, types.NewPathError("exists", path, nil, err)
+	}
+
+	_, err = client.Stat(fs.fullPath(path))
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return false, convertError("exists", path, err)
+}
+
+// convertError 将SFTP返回的错误转换为通用错误
+// pkg/sftp 已将状态码转换为 os.ErrNotExist、os.ErrPermission
+func convertError(op, path string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, os.ErrNotExist):
+		return types.NewPathError(op, path, types.ErrNotFound, err)
+	case errors.Is(err, os.ErrPermission):
+		return types.NewPathError(op, path, types.ErrPermission, err)
+	case errors.Is(err, os.ErrExist):
+		return types.NewPathError(op, path, types.ErrAlreadyExists, err)
+	}
+	return types.NewPathError(op, path, nil, err)
+}
@@ -0,0 +1,361 @@
+package sftp_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/pkg/sftp"
+	sftpfs "github.com/yu1ec/go-filesystem/driver/sftp"
+	"github.com/yu1ec/go-filesystem/internal/drivertest"
+	"github.com/yu1ec/go-filesystem/types"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	testUser     = "tester"
+	testPassword = "secret"
+)
+
+// testServer 进程内的SFTP服务器，文件直接读写本地磁盘
+type testServer struct {
+	addr      string
+	port      int
+	hostKey   ssh.PublicKey
+	clientKey string // 允许登录的PEM格式私钥
+	listener  net.Listener
+}
+
+func newTestServer(t *testing.T) *testServer {
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientPub, clientPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(clientPriv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorizedKey, err := ssh.NewPublicKey(clientPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == testUser && string(password) == testPassword {
+				return nil, nil
+			}
+			return nil, errors.New("密码错误")
+		},
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == testUser && bytes.Equal(key.Marshal(), authorizedKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("公钥未授权")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &testServer{
+		addr:      listener.Addr().String(),
+		port:      listener.Addr().(*net.TCPAddr).Port,
+		hostKey:   hostSigner.PublicKey(),
+		clientKey: string(pem.EncodeToMemory(block)),
+		listener:  listener,
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveConn(conn, config)
+		}
+	}()
+	return server
+}
+
+// serveConn 处理一个SSH连接，只支持 sftp 子系统
+func serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				req.Reply(ok, nil)
+				if ok {
+					server, err := sftp.NewServer(channel)
+					if err == nil {
+						server.Serve()
+					}
+					channel.Close()
+				}
+			}
+		}()
+	}
+}
+
+func setupTestStorage(t *testing.T) (*sftpfs.SftpFilesystem, *testServer, string) {
+	server := newTestServer(t)
+	root := t.TempDir()
+	fs, err := sftpfs.NewStorage(sftpfs.Server{
+		Host:     "127.0.0.1",
+		Port:     server.port,
+		Username: testUser,
+		Password: testPassword,
+		HostKey:  string(ssh.MarshalAuthorizedKey(server.hostKey)),
+		Root:     root,
+	})
+	if err != nil {
+		t.Fatalf("NewStorage失败：%v", err)
+	}
+	t.Cleanup(func() { fs.Close() })
+	return fs, server, root
+}
+
+func TestSftpFilesystem(t *testing.T) {
+	fs, _, root := setupTestStorage(t)
+	drivertest.Run(t, fs)
+	ctx := context.Background()
+
+	t.Run("写入根目录", func(t *testing.T) {
+		if err := fs.Put(ctx, "dir/sub/test.txt", []byte("测试数据")); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+		if _, err := os.Stat(filepath.Join(root, "dir/sub/test.txt")); err != nil {
+			t.Fatalf("文件未写入根目录：%v", err)
+		}
+	})
+
+	t.Run("路径不超出根目录", func(t *testing.T) {
+		if err := fs.Put(ctx, "../../escape.txt", []byte("x")); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+		if _, err := os.Stat(filepath.Join(root, "escape.txt")); err != nil {
+			t.Errorf("文件应写入根目录内：%v", err)
+		}
+	})
+
+	t.Run("删除不存在的文件", func(t *testing.T) {
+		if err := fs.Delete("missing.txt"); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("期望 ErrNotFound，实际：%v", err)
+		}
+	})
+
+	t.Run("覆盖移动", func(t *testing.T) {
+		fs.Put(ctx, "overwrite/a.txt", []byte("新内容"))
+		fs.Put(ctx, "overwrite/b.txt", []byte("旧内容"))
+		if err := fs.Move(ctx, "overwrite/a.txt", "overwrite/b.txt", false); !errors.Is(err, types.ErrAlreadyExists) {
+			t.Errorf("期望 ErrAlreadyExists，实际：%v", err)
+		}
+		if err := fs.Move(ctx, "overwrite/a.txt", "overwrite/b.txt", true); err != nil {
+			t.Fatalf("覆盖移动失败：%v", err)
+		}
+		if data, _ := fs.Get("overwrite/b.txt"); string(data) != "新内容" {
+			t.Errorf("移动后的内容不匹配：%s", string(data))
+		}
+	})
+
+	t.Run("写入失败时保留原文件", func(t *testing.T) {
+		if err := fs.Put(ctx, "atomic/a.txt", []byte("旧内容")); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+		reader := io.MultiReader(strings.NewReader("新内容"), iotest.ErrReader(errors.New("读取失败")))
+		if err := fs.PutStream(ctx, "atomic/a.txt", reader, -1); err == nil {
+			t.Fatal("读取失败时 PutStream 应该返回错误")
+		}
+		if data, _ := fs.Get("atomic/a.txt"); string(data) != "旧内容" {
+			t.Errorf("写入失败后内容应保持不变：%s", string(data))
+		}
+		entries, _ := os.ReadDir(filepath.Join(root, "atomic"))
+		if len(entries) != 1 {
+			t.Errorf("临时文件应该被删除：%v", entries)
+		}
+	})
+
+	t.Run("并发重新连接", func(t *testing.T) {
+		fs.Close()
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := fs.ExistsE(ctx, "atomic/a.txt"); err != nil {
+					t.Errorf("ExistsE失败：%v", err)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("断开后重新连接", func(t *testing.T) {
+		fs.Close()
+		if !fs.Exists("overwrite/b.txt") {
+			t.Error("重新连接后文件应该存在")
+		}
+	})
+}
+
+// TestSftpFilesystem_WithoutPosixRename 服务器不支持 posix-rename 扩展时的覆盖写入和移动
+func TestSftpFilesystem_WithoutPosixRename(t *testing.T) {
+	if err := sftp.SetSFTPExtensions("hardlink@openssh.com", "statvfs@openssh.com"); err != nil {
+		t.Fatalf("SetSFTPExtensions失败：%v", err)
+	}
+	t.Cleanup(func() {
+		sftp.SetSFTPExtensions("hardlink@openssh.com", "posix-rename@openssh.com", "statvfs@openssh.com")
+	})
+	fs, _, _ := setupTestStorage(t)
+	ctx := context.Background()
+
+	if err := fs.Put(ctx, "a.txt", []byte("新内容")); err != nil {
+		t.Fatalf("Put失败：%v", err)
+	}
+	if err := fs.Put(ctx, "b.txt", []byte("旧内容")); err != nil {
+		t.Fatalf("Put失败：%v", err)
+	}
+
+	t.Run("源文件不存在时保留目标文件", func(t *testing.T) {
+		if err := fs.Move(ctx, "missing.txt", "b.txt", true); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("期望 ErrNotFound，实际：%v", err)
+		}
+		if data, _ := fs.Get("b.txt"); string(data) != "旧内容" {
+			t.Errorf("目标文件不应被删除：%s", string(data))
+		}
+	})
+
+	t.Run("覆盖移动", func(t *testing.T) {
+		if err := fs.Move(ctx, "a.txt", "b.txt", true); err != nil {
+			t.Fatalf("覆盖移动失败：%v", err)
+		}
+		if data, _ := fs.Get("b.txt"); string(data) != "新内容" {
+			t.Errorf("移动后的内容不匹配：%s", string(data))
+		}
+	})
+
+	t.Run("覆盖写入", func(t *testing.T) {
+		if err := fs.Put(ctx, "b.txt", []byte("再次写入")); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+		if data, _ := fs.Get("b.txt"); string(data) != "再次写入" {
+			t.Errorf("写入后的内容不匹配：%s", string(data))
+		}
+	})
+}
+
+func TestSftpFilesystem_GetUrl(t *testing.T) {
+	fs, server, root := setupTestStorage(t)
+
+	expected := "sftp://" + testUser + "@" + server.addr + root + "/dir/a%20b.txt"
+	if url := fs.GetUrl("dir/a b.txt"); url != expected {
+		t.Errorf("URL不正确。期望：%s，实际：%s", expected, url)
+	}
+	if url, _ := fs.GetSignedUrl("dir/a b.txt", 600); url != expected {
+		t.Errorf("签名URL不正确：%s", url)
+	}
+}
+
+func TestSftpFilesystem_Auth(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	t.Run("私钥认证", func(t *testing.T) {
+		fs, err := sftpfs.NewStorage(sftpfs.Server{
+			Host:       "127.0.0.1",
+			Port:       server.port,
+			Username:   testUser,
+			PrivateKey: server.clientKey,
+			HostKey:    ssh.FingerprintSHA256(server.hostKey),
+			Root:       t.TempDir(),
+		})
+		if err != nil {
+			t.Fatalf("NewStorage失败：%v", err)
+		}
+		defer fs.Close()
+
+		if err := fs.Put(ctx, "a.txt", []byte("x")); err != nil {
+			t.Errorf("Put失败：%v", err)
+		}
+	})
+
+	t.Run("密码错误", func(t *testing.T) {
+		_, err := sftpfs.NewStorage(sftpfs.Server{
+			Host:                  "127.0.0.1",
+			Port:                  server.port,
+			Username:              testUser,
+			Password:              "wrong",
+			InsecureIgnoreHostKey: true,
+		})
+		if err == nil {
+			t.Error("密码错误时应该返回错误")
+		}
+	})
+
+	t.Run("主机公钥不匹配", func(t *testing.T) {
+		otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+		otherKey, _ := ssh.NewPublicKey(otherPub)
+		for _, hostKey := range []string{string(ssh.MarshalAuthorizedKey(otherKey)), ssh.FingerprintSHA256(otherKey)} {
+			_, err := sftpfs.NewStorage(sftpfs.Server{
+				Host:     "127.0.0.1",
+				Port:     server.port,
+				Username: testUser,
+				Password: testPassword,
+				HostKey:  hostKey,
+			})
+			if err == nil {
+				t.Errorf("主机公钥 %s 不匹配时应该返回错误", strings.TrimSpace(hostKey))
+			}
+		}
+	})
+
+	t.Run("缺少主机公钥", func(t *testing.T) {
+		_, err := sftpfs.NewStorage(sftpfs.Server{
+			Host:     "127.0.0.1",
+			Port:     server.port,
+			Username: testUser,
+			Password: testPassword,
+		})
+		if err == nil {
+			t.Error("未设置主机公钥时应该返回错误")
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package webdav
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/studio-b12/gowebdav"
+	"github.com/yu1ec/go-filesystem/types"
+)
+
+// Handler 返回代理签名URL的 http.Handler
+// 请求路径为文件路径，token 校验通过后使用保存的账号从WebDAV服务读取文件，支持 Range 和条件请求
+// 挂载在子路径时需要配合 http.StripPrefix 使用，未设置 SignKey 时拒绝所有请求
+func (fs *WebdavFilesystem) Handler() http.Handler {
+	return http.HandlerFunc(fs.serveSigned)
+}
+
+func (fs *WebdavFilesystem) serveSigned(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	if !fs.verify(key, r.URL.Query().Get("token")) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	info, err := fs.Stat(r.Context(), key)
+	if err != nil || info.IsDir {
+		status := http.StatusBadGateway
+		if err == nil || errors.Is(err, types.ErrNotFound) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	// Content-Type 不能依赖 ServeContent 探测，否则会为了探测多读取一次文件
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(key))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	if etag := info.QuotedETag(); etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+
+	f := &remoteFile{client: fs.withContext(r.Context()), path: key, size: info.Size}
+	defer f.Close()
+	http.ServeContent(w, r, key, info.LastModified, f)
+}
+
+// sign 计算文件路径和过期时间的签名
+func (fs *WebdavFilesystem) sign(key, deadline string) string {
+	mac := hmac.New(sha256.New, []byte(fs.SignKey))
+	mac.Write([]byte(key + "\n" + deadline))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verify 校验 token 的签名和过期时间，token 格式为 过期时间.签名
+func (fs *WebdavFilesystem) verify(key, token string) bool {
+	deadline, signature, ok := strings.Cut(token, ".")
+	if fs.SignKey == "" || !ok {
+		return false
+	}
+	expires, err := strconv.ParseInt(deadline, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(fs.sign(key, deadline)), []byte(signature))
+}
+
+// remoteFile 按需读取WebDAV文件，供 http.ServeContent 使用
+// Seek 只记录位置，读取时才从该位置开始请求，Range 请求不会下载整个文件
+type remoteFile struct {
+	client *gowebdav.Client
+	path   string
+	size   int64
+	offset int64
+	body   io.ReadCloser
+}
+
+func (f *remoteFile) Read(p []byte) (int, error) {
+	if f.body == nil {
+		if f.offset >= f.size {
+			return 0, io.EOF
+		}
+		var err error
+		if f.offset == 0 {
+			f.body, err = f.client.ReadStream(f.path)
+		} else {
+			f.body, err = f.client.ReadStreamRange(f.path, f.offset, f.size-f.offset)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.body.Read(p)
+	f.offset += int64(n)
+	return n, err
+}
+
+func (f *remoteFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	if offset != f.offset {
+		f.Close()
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *remoteFile) Close() error {
+	if f.body == nil {
+		return nil
+	}
+	err := f.body.Close()
+	f.body = nil
+	return err
+}
@@ -0,0 +1,110 @@
+package webdav_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestWebdavFilesystem_Handler(t *testing.T) {
+	server, fs, cleanup, err := setupTestServer()
+	if err != nil {
+		t.Fatalf("Failed to setup test server: %v", err)
+	}
+	defer server.Close()
+	defer cleanup()
+
+	mux := http.NewServeMux()
+	mux.Handle("/dav/", http.StripPrefix("/dav", fs.Handler()))
+	proxy := httptest.NewServer(mux)
+	defer proxy.Close()
+	fs.ProxyUrl = proxy.URL + "/dav"
+	fs.SignKey = "test-key"
+
+	if err := fs.Put(context.Background(), "/dir/a b.txt", []byte("0123456789")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	get := func(t *testing.T, rawUrl string, header http.Header) (*http.Response, string) {
+		req, _ := http.NewRequest(http.MethodGet, rawUrl, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("请求失败：%v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	signedUrl, err := fs.GetSignedUrl("/dir/a b.txt", 60)
+	if err != nil {
+		t.Fatalf("GetSignedUrl failed: %v", err)
+	}
+
+	t.Run("签名URL", func(t *testing.T) {
+		resp, body := get(t, signedUrl, nil)
+		if resp.StatusCode != http.StatusOK || body != "0123456789" {
+			t.Errorf("期望200和文件内容，实际：%d %s", resp.StatusCode, body)
+		}
+		if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+			t.Errorf("Content-Type不正确：%s", resp.Header.Get("Content-Type"))
+		}
+	})
+
+	t.Run("拒绝无效签名", func(t *testing.T) {
+		u, _ := url.Parse(signedUrl)
+		token := u.Query().Get("token")
+		_, signature, _ := strings.Cut(token, ".")
+
+		cases := map[string]string{
+			"未签名":  proxy.URL + "/dav/dir/a%20b.txt",
+			"修改路径": proxy.URL + "/dav/dir/other.txt?token=" + url.QueryEscape(token),
+			"签名错误": strings.Replace(signedUrl, "token=", "token=1", 1),
+			"已过期":  proxy.URL + "/dav/dir/a%20b.txt?token=" + url.QueryEscape("1."+signature),
+		}
+		for name, rawUrl := range cases {
+			if resp, _ := get(t, rawUrl, nil); resp.StatusCode != http.StatusForbidden {
+				t.Errorf("%s 期望403，实际：%d", name, resp.StatusCode)
+			}
+		}
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		resp, body := get(t, signedUrl, http.Header{"Range": {"bytes=2-5"}})
+		if resp.StatusCode != http.StatusPartialContent || body != "2345" {
+			t.Errorf("期望206和部分内容，实际：%d %s", resp.StatusCode, body)
+		}
+		if resp.Header.Get("Content-Range") != "bytes 2-5/10" {
+			t.Errorf("Content-Range不正确：%s", resp.Header.Get("Content-Range"))
+		}
+	})
+
+	t.Run("条件请求", func(t *testing.T) {
+		resp, _ := get(t, signedUrl, nil)
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if etag == "" || lastModified == "" {
+			t.Fatalf("缺少 ETag 或 Last-Modified：%v", resp.Header)
+		}
+
+		if resp, _ := get(t, signedUrl, http.Header{"If-None-Match": {etag}}); resp.StatusCode != http.StatusNotModified {
+			t.Errorf("If-None-Match 期望304，实际：%d", resp.StatusCode)
+		}
+		if resp, _ := get(t, signedUrl, http.Header{"If-Modified-Since": {lastModified}}); resp.StatusCode != http.StatusNotModified {
+			t.Errorf("If-Modified-Since 期望304，实际：%d", resp.StatusCode)
+		}
+	})
+
+	t.Run("文件不存在", func(t *testing.T) {
+		missingUrl, _ := fs.GetSignedUrl("missing.txt", 60)
+		if resp, _ := get(t, missingUrl, nil); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("期望404，实际：%d", resp.StatusCode)
+		}
+	})
+}
@@ -3,30 +3,39 @@ package webdav
 import (
 	"bytes"
 	"context"
-	"fmt"
+	"errors"
 	"image"
+	"io"
+	"net/http"
 	"net/url"
+	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/studio-b12/gowebdav"
+	"github.com/yu1ec/go-filesystem/types"
 )
 
 type WebdavFilesystem struct {
-	uri      string
-	username string
-	password string
-	client   *gowebdav.Client
+	// ProxyUrl 和 SignKey 用于生成签名URL
+	// 签名URL指向 ProxyUrl，由 Handler 校验后使用保存的账号从WebDAV服务读取文件
+	ProxyUrl string // Handler 的访问地址
+	SignKey  string // 签名密钥
+
+	uri    string
+	auth   gowebdav.Authorizer
+	client *gowebdav.Client
 }
 
 func NewStorage(uri, username, password string) (*WebdavFilesystem, error) {
 	fs := &WebdavFilesystem{
-		uri:      uri,
-		username: username,
-		password: password,
+		uri: uri,
 	}
-	fs.client = gowebdav.NewClient(uri, username, password)
+	fs.auth = gowebdav.NewAutoAuth(username, password)
+	fs.client = gowebdav.NewAuthClient(uri, fs.auth)
 
 	if err := fs.client.Connect(); err != nil {
 		return nil, err
@@ -35,14 +44,37 @@ func NewStorage(uri, username, password string) (*WebdavFilesystem, error) {
 	return fs, nil
 }
 
+// withContext 获取绑定了上下文的客户端，ctx 取消后请求随之中断
+// gowebdav 不支持 context，因此为每次调用创建客户端，认证信息由 Authorizer 共享，不会重复协商
+func (fs *WebdavFilesystem) withContext(ctx context.Context) *gowebdav.Client {
+	if ctx.Done() == nil {
+		return fs.client
+	}
+
+	client := gowebdav.NewAuthClient(fs.uri, fs.auth)
+	client.SetTransport(&contextTransport{ctx: ctx, base: http.DefaultTransport})
+	return client
+}
+
+// contextTransport 将上下文注入到每个请求中
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(req.WithContext(t.ctx))
+}
+
 func (fs *WebdavFilesystem) Put(ctx context.Context, path string, data []byte) error {
+	client := fs.withContext(ctx)
 	// path包含了文件名，所以需要提取出路径的文件夹路径,然后进行创建
 	dir := filepath.Dir(path)
-	if err := fs.client.MkdirAll(dir, 0644); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+	if err := client.MkdirAll(dir, 0644); err != nil {
+		return convertError("put", path, err)
 	}
 
-	return fs.client.Write(path, data, 0644)
+	return convertError("put", path, client.Write(path, data, 0644))
 }
 
 func (fs *WebdavFilesystem) PutWithoutContext(path string, data []byte) error {
@@ -50,7 +82,55 @@ func (fs *WebdavFilesystem) PutWithoutContext(path string, data []byte) error {
 }
 
 func (fs *WebdavFilesystem) Get(path string) ([]byte, error) {
-	return fs.client.Read(path)
+	return fs.GetWithContext(context.Background(), path)
+}
+
+// GetWithContext 获取文件内容
+func (fs *WebdavFilesystem) GetWithContext(ctx context.Context, path string) ([]byte, error) {
+	data, err := fs.withContext(ctx).Read(path)
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+	return data, nil
+}
+
+// PutStream 以流的方式写入文件
+func (fs *WebdavFilesystem) PutStream(ctx context.Context, path string, reader io.Reader, size int64) error {
+	client := fs.withContext(ctx)
+	dir := filepath.Dir(path)
+	if err := client.MkdirAll(dir, 0644); err != nil {
+		return convertError("put", path, err)
+	}
+
+	return convertError("put", path, client.WriteStream(path, reader, 0644))
+}
+
+// GetStream 以流的方式读取文件
+func (fs *WebdavFilesystem) GetStream(ctx context.Context, path string) (io.ReadCloser, error) {
+	reader, err := fs.withContext(ctx).ReadStream(path)
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+	return reader, nil
+}
+
+// GetStreamRange 从 offset 开始读取 length 字节，length 小于等于0时读取到文件末尾
+func (fs *WebdavFilesystem) GetStreamRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	// 服务端不支持 Range 时 gowebdav 按 length 截取，因此需要明确的长度
+	if length <= 0 {
+		info, err := fs.Stat(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		if length = info.Size - offset; length <= 0 {
+			return io.NopCloser(strings.NewReader("")), nil
+		}
+	}
+	reader, err := fs.withContext(ctx).ReadStreamRange(path, offset, length)
+	if err != nil {
+		return nil, convertError("get", path, err)
+	}
+	return reader, nil
 }
 
 // GetUrl 获取文件完整路径 获取不带用户名密码的URL
@@ -58,18 +138,18 @@ func (fs *WebdavFilesystem) GetUrl(path string) string {
 	return strings.TrimRight(fs.uri, "/") + "/" + strings.TrimLeft(path, "/")
 }
 
-// GetSignedUrl 获取文件完整路径 获取带用户名密码的URL
+// GetSignedUrl 获取带过期时间的签名URL
+// URL指向 ProxyUrl，不包含账号信息，需要配合 Handler 使用
+// expires: 过期时间 单位/秒
 func (fs *WebdavFilesystem) GetSignedUrl(filePath string, expires int64) (string, error) {
-	// 获取当前uri的协议
-	u, err := url.Parse(fs.uri)
-	if err != nil {
-		return "", err
+	if fs.ProxyUrl == "" || fs.SignKey == "" {
+		return "", errors.New("proxy url and sign key are required for signed urls")
 	}
 
-	u.User = url.UserPassword(fs.username, fs.password)
-	u.Path = path.Join(u.Path, filePath)
-
-	return u.String(), nil
+	key := strings.TrimPrefix(path.Clean("/"+filePath), "/")
+	deadline := strconv.FormatInt(time.Now().Unix()+expires, 10)
+	query := url.Values{"token": {deadline + "." + fs.sign(key, deadline)}}
+	return strings.TrimRight(fs.ProxyUrl, "/") + "/" + (&url.URL{Path: key}).EscapedPath() + "?" + query.Encode(), nil
 }
 
 // MustGetSignedUrl 获取签名URL
@@ -82,7 +162,12 @@ func (fs *WebdavFilesystem) MustGetSignedUrl(path string, expires int64) string
 }
 
 func (fs *WebdavFilesystem) GetImageWidthHeight(path string) (int, int, error) {
-	data, err := fs.client.Read(path)
+	return fs.GetImageWidthHeightWithContext(context.Background(), path)
+}
+
+// GetImageWidthHeightWithContext 获取图片的宽高
+func (fs *WebdavFilesystem) GetImageWidthHeightWithContext(ctx context.Context, path string) (int, int, error) {
+	data, err := fs.GetWithContext(ctx, path)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -98,11 +183,148 @@ func (fs *WebdavFilesystem) GetImageWidthHeight(path string) (int, int, error) {
 }
 
 func (fs *WebdavFilesystem) Delete(path string) error {
-	return fs.client.Remove(path)
+	return fs.DeleteWithContext(context.Background(), path)
+}
+
+// DeleteWithContext 删除文件
+// gowebdav 删除时忽略404，因此先确认文件存在
+func (fs *WebdavFilesystem) DeleteWithContext(ctx context.Context, path string) error {
+	client := fs.withContext(ctx)
+	if _, err := client.Stat(path); err != nil {
+		return convertError("delete", path, err)
+	}
+	return convertError("delete", path, client.Remove(path))
 }
 
 // Exists 判断文件是否存在
 func (fs *WebdavFilesystem) Exists(path string) bool {
-	_, err := fs.client.Stat(path)
-	return err == nil
+	return fs.ExistsWithContext(context.Background(), path)
+}
+
+// ExistsWithContext 判断文件是否存在 无法判断时返回 false，需要区分时使用 ExistsE
+func (fs *WebdavFilesystem) ExistsWithContext(ctx context.Context, path string) bool {
+	exists, _ := fs.ExistsE(ctx, path)
+	return exists
+}
+
+// ExistsE 判断文件是否存在
+// 服务端返回404时返回 false 和 nil，网络错误、认证失败等无法判断的情况返回错误
+func (fs *WebdavFilesystem) ExistsE(ctx context.Context, path string) (bool, error) {
+	_, err := fs.withContext(ctx).Stat(path)
+	if err == nil {
+		return true, nil
+	}
+	if gowebdav.IsErrNotFound(err) {
+		return false, nil
+	}
+	return false, convertError("exists", path, err)
+}
+
+// Stat 获取文件信息 通过PROPFIND请求获取
+func (fs *WebdavFilesystem) Stat(ctx context.Context, path string) (types.FileInfo, error) {
+	info, err := fs.withContext(ctx).Stat(path)
+	if err != nil {
+		return types.FileInfo{}, convertError("stat", path, err)
+	}
+
+	return toFileInfo(path, info), nil
+}
+
+// List 列举目录下的文件
+// prefix 为目录路径，目录不存在时返回空列表
+func (fs *WebdavFilesystem) List(ctx context.Context, prefix string, opts types.ListOptions) (types.ListResult, error) {
+	var files []types.FileInfo
+	if err := fs.readDir(ctx, fs.withContext(ctx), prefix, opts.Recursive, &files); err != nil && !gowebdav.IsErrNotFound(err) {
+		return types.ListResult{}, convertError("list", prefix, err)
+	}
+	return types.Paginate(files, opts.Cursor, opts.Limit), nil
+}
+
+// readDir 读取目录，递归时只收集文件
+func (fs *WebdavFilesystem) readDir(ctx context.Context, client *gowebdav.Client, dir string, recursive bool, files *[]types.FileInfo) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	infos, err := client.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, info := range infos {
+		filePath := path.Join(dir, info.Name())
+		if info.IsDir() && recursive {
+			if err := fs.readDir(ctx, client, filePath, recursive, files); err != nil {
+				return err
+			}
+			continue
+		}
+		*files = append(*files, toFileInfo(filePath, info))
+	}
+	return nil
+}
+
+// toFileInfo 将 gowebdav 返回的文件信息转换为通用的文件信息
+// 目录路径以 / 结尾
+func toFileInfo(filePath string, info os.FileInfo) types.FileInfo {
+	if info.IsDir() {
+		return types.FileInfo{
+			Path:         strings.TrimSuffix(filePath, "/") + "/",
+			LastModified: info.ModTime(),
+			IsDir:        true,
+		}
+	}
+
+	fileInfo := types.FileInfo{
+		Path:         filePath,
+		Size:         info.Size(),
+		LastModified: info.ModTime(),
+	}
+	switch f := info.(type) {
+	case *gowebdav.File:
+		fileInfo.ContentType = f.ContentType()
+		fileInfo.ETag = f.ETag()
+	case gowebdav.File:
+		fileInfo.ContentType = f.ContentType()
+		fileInfo.ETag = f.ETag()
+	}
+	return fileInfo
+}
+
+// Copy 复制文件 使用WebDAV的COPY方法
+func (fs *WebdavFilesystem) Copy(ctx context.Context, src, dst string, overwrite bool) error {
+	return convertCopyMoveError("copy", src, dst, fs.withContext(ctx).Copy(src, dst, overwrite))
+}
+
+// Move 移动文件 使用WebDAV的MOVE方法
+func (fs *WebdavFilesystem) Move(ctx context.Context, src, dst string, overwrite bool) error {
+	return convertCopyMoveError("move", src, dst, fs.withContext(ctx).Rename(src, dst, overwrite))
+}
+
+// convertCopyMoveError 目标已存在时服务端返回 412 Precondition Failed，其余错误归属于源文件
+func convertCopyMoveError(op, src, dst string, err error) error {
+	if gowebdav.IsErrCode(err, http.StatusPreconditionFailed) {
+		return types.NewPathError(op, dst, types.ErrAlreadyExists, err)
+	}
+	return convertError(op, src, err)
+}
+
+// convertError 将 gowebdav 返回的状态码错误转换为通用错误
+func convertError(op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var statusErr gowebdav.StatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.Status {
+		case http.StatusNotFound:
+			return types.NewPathError(op, path, types.ErrNotFound, err)
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return types.NewPathError(op, path, types.ErrPermission, err)
+		case http.StatusPreconditionFailed:
+			return types.NewPathError(op, path, types.ErrAlreadyExists, err)
+		}
+	}
+	return types.NewPathError(op, path, nil, err)
 }
@@ -3,15 +3,20 @@ package webdav_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"image"
 	"image/png"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/yu1ec/go-filesystem/driver/webdav"
+	"github.com/yu1ec/go-filesystem/internal/drivertest"
+	"github.com/yu1ec/go-filesystem/types"
 	xwebdav "golang.org/x/net/webdav"
 )
 
@@ -100,6 +105,29 @@ func TestWebdavFilesystem(t *testing.T) {
 		}
 	})
 
+	t.Run("PutStream and GetStream", func(t *testing.T) {
+		data := []byte("test stream data")
+		err := fs.PutStream(context.Background(), "/stream/test_stream.txt", bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("PutStream failed: %v", err)
+		}
+
+		reader, err := fs.GetStream(context.Background(), "/stream/test_stream.txt")
+		if err != nil {
+			t.Fatalf("GetStream failed: %v", err)
+		}
+		defer reader.Close()
+
+		retrieved, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("Read stream failed: %v", err)
+		}
+
+		if !bytes.Equal(data, retrieved) {
+			t.Errorf("Retrieved data doesn't match. Expected %s, got %s", data, retrieved)
+		}
+	})
+
 	t.Run("GetUrl", func(t *testing.T) {
 		url := fs.GetUrl("/test.txt")
 		expected := server.URL + "/test.txt"
@@ -109,22 +137,31 @@ func TestWebdavFilesystem(t *testing.T) {
 	})
 
 	t.Run("GetSignedUrl", func(t *testing.T) {
+		// 未配置代理时不能生成签名URL，也不应在URL中携带账号
+		if _, err := fs.GetSignedUrl("/test.txt", 3600); err == nil {
+			t.Error("未配置代理时期望返回错误")
+		}
+
+		fs.ProxyUrl = "http://proxy.example.com/dav"
+		fs.SignKey = "test-key"
+		defer func() { fs.ProxyUrl, fs.SignKey = "", "" }()
+
 		url, err := fs.GetSignedUrl("/test.txt", 3600)
 		if err != nil {
 			t.Fatalf("GetSignedUrl failed: %v", err)
 		}
-		expected := "http://testuser:testpass@" + server.URL[7:] + "/test.txt"
-		if url != expected {
-			t.Errorf("GetSignedUrl returned incorrect URL. Expected %s, got %s", expected, url)
+		if !strings.HasPrefix(url, "http://proxy.example.com/dav/test.txt?token=") || strings.Contains(url, "testpass") {
+			t.Errorf("GetSignedUrl returned incorrect URL: %s", url)
 		}
 	})
 
 	t.Run("MustGetSignedUrl", func(t *testing.T) {
-		url := fs.MustGetSignedUrl("/test.txt", 3600)
-		expected := "http://testuser:testpass@" + server.URL[7:] + "/test.txt"
-		if url != expected {
-			t.Errorf("MustGetSignedUrl returned incorrect URL. Expected %s, got %s", expected, url)
-		}
+		defer func() {
+			if recover() == nil {
+				t.Error("未配置代理时 MustGetSignedUrl 应该 panic")
+			}
+		}()
+		fs.MustGetSignedUrl("/test.txt", 3600)
 	})
 
 	t.Run("GetImageWidthHeight", func(t *testing.T) {
@@ -170,6 +207,132 @@ func TestWebdavFilesystem(t *testing.T) {
 		if err == nil {
 			t.Error("Expected error when getting deleted file, got nil")
 		}
+
+		if err := fs.Delete(testPath); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound when deleting missing file, got %v", err)
+		}
+	})
+
+	t.Run("Stat", func(t *testing.T) {
+		testData := []byte("test file for stat")
+		testPath := "/test_stat.txt"
+
+		err := fs.Put(context.Background(), testPath, testData)
+		if err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		info, err := fs.Stat(context.Background(), testPath)
+		if err != nil {
+			t.Fatalf("Stat failed: %v", err)
+		}
+
+		if info.Size != int64(len(testData)) {
+			t.Errorf("Stat size mismatch. Expected %d, got %d", len(testData), info.Size)
+		}
+		if info.LastModified.IsZero() {
+			t.Error("Expected LastModified to be set")
+		}
+		if info.ContentType == "" || info.ETag == "" {
+			t.Errorf("Expected ContentType and ETag to be set, got %q and %q", info.ContentType, info.ETag)
+		}
+
+		_, err = fs.Stat(context.Background(), "/nonexistent.txt")
+		if !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound when stating non-existent file, got %v", err)
+		}
+
+		_, err = fs.Get("/nonexistent.txt")
+		if !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound when getting non-existent file, got %v", err)
+		}
+	})
+
+	t.Run("GetStreamRange", func(t *testing.T) {
+		drivertest.RunRangeReader(t, fs, fs)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		for _, p := range []string{"/list/a.txt", "/list/sub/b.txt", "/list/sub/deep/c.txt"} {
+			if err := fs.Put(context.Background(), p, []byte(p)); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+		}
+
+		result, err := fs.List(context.Background(), "/list", types.ListOptions{})
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if got := drivertest.ListPaths(result.Files); got != "/list/a.txt,/list/sub/" {
+			t.Errorf("List mismatch, got %s", got)
+		}
+
+		result, err = fs.List(context.Background(), "/list", types.ListOptions{Recursive: true, Limit: 2})
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if got := drivertest.ListPaths(result.Files); got != "/list/a.txt,/list/sub/b.txt" {
+			t.Errorf("Recursive list mismatch, got %s", got)
+		}
+		if result.NextCursor == "" {
+			t.Fatal("Expected next cursor")
+		}
+
+		result, err = fs.List(context.Background(), "/list", types.ListOptions{Recursive: true, Limit: 2, Cursor: result.NextCursor})
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if got := drivertest.ListPaths(result.Files); got != "/list/sub/deep/c.txt" || result.NextCursor != "" {
+			t.Errorf("Second page mismatch, got %s, cursor %q", got, result.NextCursor)
+		}
+	})
+
+	t.Run("Copy and Move", func(t *testing.T) {
+		data := []byte("copy and move")
+		if err := fs.Put(context.Background(), "/copy/src.txt", data); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+
+		if err := fs.Copy(context.Background(), "/copy/src.txt", "/copy/dst/copied.txt", false); err != nil {
+			t.Fatalf("Copy failed: %v", err)
+		}
+		copied, err := fs.Get("/copy/dst/copied.txt")
+		if err != nil || !bytes.Equal(copied, data) {
+			t.Errorf("Copied data doesn't match: %s, %v", copied, err)
+		}
+
+		err = fs.Copy(context.Background(), "/copy/src.txt", "/copy/dst/copied.txt", false)
+		if !errors.Is(err, types.ErrAlreadyExists) {
+			t.Errorf("Expected ErrAlreadyExists, got %v", err)
+		}
+
+		if err := fs.Move(context.Background(), "/copy/src.txt", "/copy/dst/copied.txt", true); err != nil {
+			t.Fatalf("Move failed: %v", err)
+		}
+		if fs.Exists("/copy/src.txt") {
+			t.Error("Expected source file to be moved")
+		}
+	})
+
+	t.Run("Canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := fs.GetWithContext(ctx, "/test.txt"); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled from GetWithContext, got %v", err)
+		}
+		if err := fs.Put(ctx, "/canceled.txt", []byte("data")); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled from Put, got %v", err)
+		}
+		if fs.ExistsWithContext(ctx, "/test.txt") {
+			t.Error("Expected ExistsWithContext to fail with canceled context")
+		}
+
+		// 未取消的上下文正常工作
+		data, err := fs.GetWithContext(context.Background(), "/test.txt")
+		if err != nil || len(data) == 0 {
+			t.Errorf("GetWithContext failed: %v", err)
+		}
 	})
 
 	t.Run("Exists", func(t *testing.T) {
@@ -200,3 +363,44 @@ func TestWebdavFilesystem(t *testing.T) {
 
 	})
 }
+
+func TestWebdavFilesystem_ExistsE(t *testing.T) {
+	server, fs, cleanup, err := setupTestServer()
+	if err != nil {
+		t.Fatalf("Failed to setup test server: %v", err)
+	}
+	defer server.Close()
+	defer cleanup()
+
+	if err := fs.Put(context.Background(), "/exists_e.txt", []byte("data")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	exists, err := fs.ExistsE(context.Background(), "/exists_e.txt")
+	if err != nil || !exists {
+		t.Errorf("Expected file to exist, got %v, %v", exists, err)
+	}
+
+	exists, err = fs.ExistsE(context.Background(), "/nonexistent.txt")
+	if err != nil || exists {
+		t.Errorf("Expected file to not exist without error, got %v, %v", exists, err)
+	}
+
+	// 服务端异常时无法判断，需要返回错误
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			return
+		}
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+
+	brokenFs, err := webdav.NewStorage(broken.URL, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	exists, err = brokenFs.ExistsE(context.Background(), "/exists_e.txt")
+	if err == nil || exists {
+		t.Errorf("Expected error from broken server, got %v, %v", exists, err)
+	}
+}
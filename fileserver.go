@@ -0,0 +1,224 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// FileServerOptions FileServer 的选项
+type FileServerOptions struct {
+	Attachment bool // 以附件形式下载，为 false 时由浏览器直接打开
+
+	// Filename 返回写入 Content-Disposition 的文件名
+	// 为空时使用文件路径的最后一段
+	Filename func(path string) string
+}
+
+// FileServer 返回提供文件下载的 http.Handler，适用于任意驱动
+// 请求路径即文件路径，挂载在子路径时需要配合 http.StripPrefix 使用
+// 文件信息通过 Stat 获取，内容以流的方式读取，支持 Range 和条件请求
+// 驱动实现了 RangeReader 时 Range 请求只读取需要的部分
+func FileServer(fsys Filesystem, opts FileServerOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		if key == "" || strings.HasSuffix(key, "/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		info, err := fsys.Stat(r.Context(), key)
+		if err == nil && info.IsDir {
+			err = ErrNotFound
+		}
+		if err != nil {
+			status := statusCode(err)
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+
+		header := w.Header()
+		contentType := info.ContentType
+		if contentType == "" {
+			contentType = mime.TypeByExtension(path.Ext(key))
+		}
+		// 仍然未知时由 ServeContent 读取开头的内容探测
+		if contentType != "" {
+			header.Set("Content-Type", contentType)
+		}
+		if etag := info.QuotedETag(); etag != "" {
+			header.Set("ETag", etag)
+		}
+
+		filename := path.Base(key)
+		if opts.Filename != nil {
+			filename = opts.Filename(key)
+		}
+		disposition := "inline"
+		if opts.Attachment {
+			disposition = "attachment"
+		}
+		header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": filename}))
+
+		f := &streamSeeker{ctx: r.Context(), fsys: fsys, path: key, size: info.Size}
+		f.rangeStart, f.rangeLength = singleRange(r.Header.Get("Range"), info.Size)
+		defer f.Close()
+		http.ServeContent(w, r, "", info.LastModified, f)
+	})
+}
+
+// statusCode 将文件系统的错误转换为HTTP状态码
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrInvalidPath):
+		return http.StatusNotFound
+	case errors.Is(err, ErrPermission):
+		return http.StatusForbidden
+	}
+	// 其他错误来自存储服务
+	return http.StatusBadGateway
+}
+
+// singleRange 解析只包含一个范围的 Range 请求头，返回开始位置和长度
+// 无法解析或包含多个范围时长度为0，读取时按到文件末尾处理
+func singleRange(header string, size int64) (start, length int64) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return 0, 0
+	}
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return 0, 0
+	}
+	if first == "" {
+		// bytes=-n 表示最后 n 个字节
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0
+		}
+		n = min(n, size)
+		return size - n, n
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0
+	}
+	if last == "" {
+		return start, size - start
+	}
+	end, err := strconv.ParseInt(last, 10, 64)
+	if err != nil || end < start {
+		return 0, 0
+	}
+	return start, min(end, size-1) - start + 1
+}
+
+// streamSeeker 将 GetStream 包装为 io.ReadSeeker，供 http.ServeContent 使用
+// Seek 只记录位置，读取时才打开文件，优先按范围读取，流本身支持 Seek 时直接移动，否则跳过前面的内容
+type streamSeeker struct {
+	ctx    context.Context
+	fsys   Filesystem
+	path   string
+	size   int64
+	offset int64
+	body   io.ReadCloser
+
+	// 请求的范围，从 rangeStart 开始读取时只读取 rangeLength 字节
+	rangeStart, rangeLength int64
+}
+
+func (f *streamSeeker) Read(p []byte) (int, error) {
+	if f.body == nil {
+		if f.offset >= f.size {
+			return 0, io.EOF
+		}
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.body.Read(p)
+	f.offset += int64(n)
+	return n, err
+}
+
+func (f *streamSeeker) open() error {
+	if rr, ok := f.fsys.(RangeReader); ok && f.offset > 0 {
+		length := f.size - f.offset
+		if f.offset == f.rangeStart && f.rangeLength > 0 {
+			length = f.rangeLength
+		}
+		body, err := rr.GetStreamRange(f.ctx, f.path, f.offset, length)
+		if err != nil {
+			return err
+		}
+		f.body = body
+		return nil
+	}
+
+	body, err := f.fsys.GetStream(f.ctx, f.path)
+	if err != nil {
+		return err
+	}
+
+	if seeker, ok := body.(io.Seeker); ok {
+		_, err = seeker.Seek(f.offset, io.SeekStart)
+	} else {
+		_, err = io.CopyN(io.Discard, body, f.offset)
+	}
+	if err != nil {
+		body.Close()
+		return err
+	}
+	f.body = body
+	return nil
+}
+
+func (f *streamSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	if offset != f.offset && f.body != nil {
+		// 已打开的流支持 Seek 时直接移动，否则关闭后在下次读取时重新打开
+		if seeker, ok := f.body.(io.Seeker); ok {
+			if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+				return 0, err
+			}
+		} else {
+			f.Close()
+		}
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *streamSeeker) Close() error {
+	if f.body == nil {
+		return nil
+	}
+	err := f.body.Close()
+	f.body = nil
+	return err
+}
@@ -0,0 +1,213 @@
+package filesystem_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yu1ec/go-filesystem"
+	"github.com/yu1ec/go-filesystem/driver/local"
+	"github.com/yu1ec/go-filesystem/driver/memory"
+)
+
+func TestFileServer(t *testing.T) {
+	// memory 返回的流不支持 Seek，local 返回 *os.File，两种情况都需要覆盖
+	drivers := map[string]filesystem.Filesystem{
+		"memory":   memory.NewStorage(""),
+		"local":    local.NewStorage(t.TempDir(), ""),
+		"bareETag": bareETagFilesystem{memory.NewStorage("")},
+		"range":    &rangeFilesystem{Filesystem: memory.NewStorage("")},
+	}
+
+	for name, fsys := range drivers {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if err := fsys.Put(ctx, "docs/报告.txt", []byte("0123456789")); err != nil {
+				t.Fatalf("Put失败：%v", err)
+			}
+			if err := fsys.Put(ctx, "docs/data", []byte("\x89PNG\r\n\x1a\n0000")); err != nil {
+				t.Fatalf("Put失败：%v", err)
+			}
+			testFileServer(t, fsys)
+		})
+	}
+}
+
+// bareETagFilesystem 返回不带引号的 ETag，模拟七牛、GCS 等驱动
+type bareETagFilesystem struct {
+	filesystem.Filesystem
+}
+
+func (fs bareETagFilesystem) Stat(ctx context.Context, path string) (filesystem.FileInfo, error) {
+	info, err := fs.Filesystem.Stat(ctx, path)
+	info.ETag = strings.Trim(info.ETag, `"`)
+	return info, err
+}
+
+// rangeFilesystem 实现 RangeReader 并记录调用的范围
+type rangeFilesystem struct {
+	filesystem.Filesystem
+	ranges []string
+}
+
+func (fs *rangeFilesystem) GetStreamRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	fs.ranges = append(fs.ranges, fmt.Sprintf("%d-%d", offset, length))
+	data, err := fs.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	data = data[offset:]
+	if length > 0 {
+		data = data[:length]
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func TestFileServer_RangeReader(t *testing.T) {
+	fsys := &rangeFilesystem{Filesystem: memory.NewStorage("")}
+	if err := fsys.Put(context.Background(), "a.txt", []byte("0123456789")); err != nil {
+		t.Fatalf("Put失败：%v", err)
+	}
+	handler := filesystem.FileServer(fsys, filesystem.FileServerOptions{})
+
+	for _, r := range []string{"bytes=2-5", "bytes=-3", "bytes=0-1"} {
+		req := httptest.NewRequest(http.MethodGet, "/a.txt", nil)
+		req.Header.Set("Range", r)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	// 从头开始的范围直接读取流，不需要按范围读取
+	if got := strings.Join(fsys.ranges, ","); got != "2-4,7-3" {
+		t.Errorf("按范围读取的调用不正确：%s", got)
+	}
+}
+
+func TestFileServer_BackendError(t *testing.T) {
+	fsys := memory.NewStorage("")
+	fsys.SetHook(func(ctx context.Context, op, path string) error {
+		return errors.New("连接被重置")
+	})
+
+	rec := httptest.NewRecorder()
+	filesystem.FileServer(fsys, filesystem.FileServerOptions{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/a.txt", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("存储服务出错时期望502，实际：%d", rec.Code)
+	}
+}
+
+func testFileServer(t *testing.T, fsys filesystem.Filesystem) {
+	serve := func(t *testing.T, handler http.Handler, method, target string, header http.Header) (*http.Response, string) {
+		req := httptest.NewRequest(method, target, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		resp := rec.Result()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+	handler := http.StripPrefix("/files", filesystem.FileServer(fsys, filesystem.FileServerOptions{}))
+
+	t.Run("GET", func(t *testing.T) {
+		resp, body := serve(t, handler, http.MethodGet, "/files/docs/%E6%8A%A5%E5%91%8A.txt", nil)
+		if resp.StatusCode != http.StatusOK || body != "0123456789" {
+			t.Fatalf("期望200和文件内容，实际：%d %s", resp.StatusCode, body)
+		}
+		expected := map[string]string{
+			"Content-Type":        "text/plain; charset=utf-8",
+			"Content-Length":      "10",
+			"Content-Disposition": "inline; filename*=utf-8''%E6%8A%A5%E5%91%8A.txt",
+		}
+		for key, value := range expected {
+			if got := resp.Header.Get(key); got != value {
+				t.Errorf("%s 期望 %s，实际 %s", key, value, got)
+			}
+		}
+		if resp.Header.Get("ETag") == "" || resp.Header.Get("Last-Modified") == "" {
+			t.Errorf("缺少 ETag 或 Last-Modified：%v", resp.Header)
+		}
+	})
+
+	t.Run("探测Content-Type", func(t *testing.T) {
+		resp, body := serve(t, handler, http.MethodGet, "/files/docs/data", nil)
+		if resp.Header.Get("Content-Type") != "image/png" || len(body) != 12 {
+			t.Errorf("探测的类型不正确：%s，长度：%d", resp.Header.Get("Content-Type"), len(body))
+		}
+	})
+
+	t.Run("HEAD", func(t *testing.T) {
+		resp, body := serve(t, handler, http.MethodHead, "/files/docs/%E6%8A%A5%E5%91%8A.txt", nil)
+		if resp.StatusCode != http.StatusOK || body != "" || resp.Header.Get("Content-Length") != "10" {
+			t.Errorf("HEAD响应不正确：%d %q %v", resp.StatusCode, body, resp.Header)
+		}
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		resp, body := serve(t, handler, http.MethodGet, "/files/docs/%E6%8A%A5%E5%91%8A.txt", http.Header{"Range": {"bytes=2-5"}})
+		if resp.StatusCode != http.StatusPartialContent || body != "2345" {
+			t.Errorf("期望206和部分内容，实际：%d %s", resp.StatusCode, body)
+		}
+		if resp.Header.Get("Content-Range") != "bytes 2-5/10" {
+			t.Errorf("Content-Range不正确：%s", resp.Header.Get("Content-Range"))
+		}
+
+		resp, body = serve(t, handler, http.MethodGet, "/files/docs/%E6%8A%A5%E5%91%8A.txt", http.Header{"Range": {"bytes=-3"}})
+		if resp.StatusCode != http.StatusPartialContent || body != "789" {
+			t.Errorf("期望206和末尾内容，实际：%d %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("条件请求", func(t *testing.T) {
+		resp, _ := serve(t, handler, http.MethodGet, "/files/docs/%E6%8A%A5%E5%91%8A.txt", nil)
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) {
+			t.Fatalf("ETag 应该带引号：%s", etag)
+		}
+
+		if resp, _ := serve(t, handler, http.MethodGet, "/files/docs/%E6%8A%A5%E5%91%8A.txt", http.Header{"If-None-Match": {etag}}); resp.StatusCode != http.StatusNotModified {
+			t.Errorf("If-None-Match 期望304，实际：%d", resp.StatusCode)
+		}
+		if resp, _ := serve(t, handler, http.MethodGet, "/files/docs/%E6%8A%A5%E5%91%8A.txt", http.Header{"If-Modified-Since": {lastModified}}); resp.StatusCode != http.StatusNotModified {
+			t.Errorf("If-Modified-Since 期望304，实际：%d", resp.StatusCode)
+		}
+		// If-Range 匹配时返回部分内容
+		resp, body := serve(t, handler, http.MethodGet, "/files/docs/%E6%8A%A5%E5%91%8A.txt", http.Header{"Range": {"bytes=0-1"}, "If-Range": {etag}})
+		if resp.StatusCode != http.StatusPartialContent || body != "01" {
+			t.Errorf("If-Range 期望206，实际：%d %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("下载文件名", func(t *testing.T) {
+		handler := filesystem.FileServer(fsys, filesystem.FileServerOptions{
+			Attachment: true,
+			Filename:   func(path string) string { return "report.txt" },
+		})
+		resp, _ := serve(t, handler, http.MethodGet, "/docs/%E6%8A%A5%E5%91%8A.txt", nil)
+		if got := resp.Header.Get("Content-Disposition"); got != "attachment; filename=report.txt" {
+			t.Errorf("Content-Disposition不正确：%s", got)
+		}
+	})
+
+	t.Run("错误状态码", func(t *testing.T) {
+		cases := []struct {
+			method, target string
+			status         int
+		}{
+			{http.MethodGet, "/files/missing.txt", http.StatusNotFound},
+			{http.MethodGet, "/files/docs/", http.StatusNotFound},
+			{http.MethodGet, "/files/docs", http.StatusNotFound},
+			{http.MethodPost, "/files/docs/data", http.StatusMethodNotAllowed},
+		}
+		for _, c := range cases {
+			if resp, _ := serve(t, handler, c.method, c.target, nil); resp.StatusCode != c.status {
+				t.Errorf("%s %s 期望 %d，实际：%d", c.method, c.target, c.status, resp.StatusCode)
+			}
+		}
+	})
+}
@@ -1,83 +1,154 @@
 package filesystem
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
 	"github.com/yu1ec/go-filesystem/config"
-	"github.com/yu1ec/go-filesystem/driver/local"
 	"github.com/yu1ec/go-filesystem/driver/qiniu"
-	"github.com/yu1ec/go-filesystem/driver/webdav"
+	"github.com/yu1ec/go-filesystem/types"
 
 	"gopkg.in/yaml.v3"
 )
 
+// FileInfo 文件信息
+type FileInfo = types.FileInfo
+
+// ListOptions 列举选项
+type ListOptions = types.ListOptions
+
+// ListResult 列举结果
+type ListResult = types.ListResult
+
+// 各驱动共用的错误，可以使用 errors.Is 判断，不需要关心具体的驱动
+var (
+	ErrNotFound      = types.ErrNotFound      // 文件不存在
+	ErrPermission    = types.ErrPermission    // 没有权限
+	ErrAlreadyExists = types.ErrAlreadyExists // 目标文件已存在
+	ErrInvalidPath   = types.ErrInvalidPath   // 路径不合法
+	ErrReadOnly      = types.ErrReadOnly      // 文件系统只读
+)
+
+// PathError 记录出错的操作和文件路径
+type PathError = types.PathError
+
 // Filesystem 文件系统接口
 type Filesystem interface {
-	Put(ctx context.Context, path string, data []byte) error // 将数据写入文件
-	PutWithoutContext(path string, data []byte) error        // 将数据写入文件不带上下文
-	Get(path string) ([]byte, error)                         // 获取文件内容
-	GetUrl(path string) string                               // 获取文件的URL
+	Put(ctx context.Context, path string, data []byte) error         // 将数据写入文件
+	PutWithoutContext(path string, data []byte) error                // 将数据写入文件不带上下文
+	Get(path string) ([]byte, error)                                 // 获取文件内容
+	GetWithContext(ctx context.Context, path string) ([]byte, error) // 获取文件内容，随 ctx 取消
+	GetUrl(path string) string                                       // 获取文件的URL
+
+	// PutStream 以流的方式写入文件，内存占用与文件大小无关
+	// size: 数据长度，未知时传 -1
+	PutStream(ctx context.Context, path string, reader io.Reader, size int64) error
+	// GetStream 以流的方式读取文件，调用方使用完毕后需要关闭
+	GetStream(ctx context.Context, path string) (io.ReadCloser, error)
 
 	// GetSignedUrl 获取签名URL
 	// path: 文件路径
 	// expires: 过期时间 单位/秒
 	GetSignedUrl(path string, expires int64) (string, error)
-	GetImageWidthHeight(path string) (int, int, error) // 获取图片的宽高
+	GetImageWidthHeight(path string) (int, int, error)                                 // 获取图片的宽高
+	GetImageWidthHeightWithContext(ctx context.Context, path string) (int, int, error) // 获取图片的宽高，随 ctx 取消
 
 	MustGetSignedUrl(path string, expires int64) string // 获取签名URL
 
-	Delete(path string) error // 删除文件
-	Exists(path string) bool  // 判断文件是否存在
+	// Stat 获取文件信息，包含大小、修改时间、MIME类型和ETag，无需下载文件内容
+	Stat(ctx context.Context, path string) (FileInfo, error)
+	// List 列举 prefix 目录下的文件，支持递归和分页，遍历全部文件可使用 Walk
+	List(ctx context.Context, prefix string, opts ListOptions) (ListResult, error)
+
+	// Copy 复制文件，优先使用服务端复制
+	// overwrite 为 false 且目标文件已存在时返回 ErrAlreadyExists
+	Copy(ctx context.Context, src, dst string, overwrite bool) error
+	// Move 移动文件，优先使用服务端移动
+	// overwrite 为 false 且目标文件已存在时返回 ErrAlreadyExists
+	Move(ctx context.Context, src, dst string, overwrite bool) error
+
+	// Delete 删除文件，文件不存在时返回 ErrNotFound
+	// S3、OSS、COS、WebDAV 等删除不存在的文件不报错的后端会先查询一次文件是否存在，
+	// 因此多一次请求，且检查与删除之间不是原子的：并发删除同一文件时可能都返回成功
+	Delete(path string) error
+	DeleteWithContext(ctx context.Context, path string) error // 删除文件，随 ctx 取消，行为同 Delete
+	Exists(path string) bool                                  // 判断文件是否存在
+	ExistsWithContext(ctx context.Context, path string) bool  // 判断文件是否存在，随 ctx 取消
+
+	// ExistsE 判断文件是否存在
+	// 文件不存在时返回 false 和 nil，权限不足、网络错误等无法判断的情况返回错误
+	// Exists 和 ExistsWithContext 在无法判断时返回 false
+	ExistsE(ctx context.Context, path string) (bool, error)
 }
 
-// NewStorage 创建文件系统
+// RangeReader 支持按范围读取的驱动实现此接口，FileServer 处理 Range 请求时不需要跳过前面的内容
+type RangeReader interface {
+	// GetStreamRange 从 offset 开始读取 length 字节，length 小于等于0时读取到文件末尾
+	GetStreamRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error)
+}
+
+// NewStorage 创建文件系统，出错时 panic，需要处理错误时使用 NewStorageWithError
 func NewStorage(driver config.FilesystemDriver) Filesystem {
-	fs, _ := NewStorageWithError(driver)
+	fs, err := NewStorageWithError(driver)
+	if err != nil {
+		panic(err)
+	}
 	return fs
 }
 
 // NewStorageWithError 带错误信息的文件系统创建
+// 驱动名称未注册时返回 ErrUnknownDriver，配置有误时返回包含全部问题的错误
 func NewStorageWithError(driver config.FilesystemDriver) (Filesystem, error) {
-	var fs Filesystem
-	var err error
-	switch driver.Name {
-	case "local":
-		var cfg config.LocalDriverConfig
-		mapToStruct(driver.Config, &cfg)
-		fs = local.NewStorage(cfg.Root, cfg.BaseUrl)
-	case "qiniu":
-		var cfg config.QiniuDriverConfig
-		mapToStruct(driver.Config, &cfg)
-
-		bucket := qiniu.Bucket{
-			Name:            cfg.Bucket,
-			Domain:          cfg.Domain,
-			TimestampEncKey: cfg.TimestampEncKey,
-			Private:         cfg.Private,
-		}
-		fs = qiniu.NewStorage(cfg.AccessKey, cfg.AccessSecret, bucket)
-	case "webdav":
-		var cfg config.WebdavDriverConfig
-		mapToStruct(driver.Config, &cfg)
-		fs, err = webdav.NewStorage(cfg.Uri, cfg.Username, cfg.Password)
-	case "":
-		panic("请正确选择文件系统配置")
-	default:
-		panic(driver.Name + "为不支持的文件系统")
+	factory, err := lookupDriver(driver.Name)
+	if err != nil {
+		return nil, err
 	}
 
-	return fs, err
+	fs, err := factory(driver.Config)
+	if err != nil {
+		return nil, fmt.Errorf("%s filesystem: %w", driver.Name, err)
+	}
+	return fs, nil
 }
 
-// mapToStruct 手动将 map[string]any 转换为结构体
-func mapToStruct(input any, output any) {
-	data, _ := yaml.Marshal(input)
-	yaml.Unmarshal(data, output)
+// DecodeConfig 将 config.FilesystemDriver 中的 Config 解码为驱动的配置结构体
+// Config 通常是 YAML 解析出的 map，也可以直接传入配置结构体
+// 配置值支持 ${VAR} 环境变量，密钥类字段支持 xxx_file 从文件读取，避免将密钥写入配置文件
+// 出现未知字段时返回错误，output 实现了 config.Validator 时会在解码后进行校验，错误会合并返回
+func DecodeConfig(input any, output any) error {
+	data, err := yaml.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("failed to encode driver config: %w", err)
+	}
+	if data, err = resolveConfig(data, output); err != nil {
+		return err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err = dec.Decode(output)
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+
+	// 类型错误时 yaml 仍会解码其余字段，继续校验以便一次报告全部问题
+	var typeErr *yaml.TypeError
+	if err != nil && !errors.As(err, &typeErr) {
+		return fmt.Errorf("failed to decode driver config: %w", err)
+	}
+
+	var validateErr error
+	if v, ok := output.(config.Validator); ok {
+		validateErr = v.Validate()
+	}
+	return errors.Join(err, validateErr)
 }
 
 // BuildUploadKey 生成上传文件的key
@@ -0,0 +1,134 @@
+package filesystem_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yu1ec/go-filesystem"
+	"github.com/yu1ec/go-filesystem/config"
+	"gopkg.in/yaml.v3"
+)
+
+func TestNewStorageWithError_InvalidConfig(t *testing.T) {
+	t.Run("拼写错误的字段", func(t *testing.T) {
+		var driver config.FilesystemDriver
+		err := yaml.Unmarshal([]byte(`
+name: qiniu
+config:
+  acess_key: ak
+  access_secret: sk
+  bucket: test
+  domain: https://cdn.example.com
+`), &driver)
+		if err != nil {
+			t.Fatalf("无法解析YAML：%v", err)
+		}
+
+		fs, err := filesystem.NewStorageWithError(driver)
+		if err == nil || fs != nil {
+			t.Fatalf("期望返回错误，实际：%v, %v", fs, err)
+		}
+		// 未知字段和缺失的必填字段应同时报告
+		if !strings.Contains(err.Error(), "acess_key") {
+			t.Errorf("错误信息应包含未知字段 acess_key：%v", err)
+		}
+		if !errors.Is(err, config.ErrRequired) || !strings.Contains(err.Error(), "access_key") {
+			t.Errorf("错误信息应包含缺失字段 access_key：%v", err)
+		}
+	})
+
+	t.Run("缺失多个必填字段", func(t *testing.T) {
+		_, err := filesystem.NewStorageWithError(config.FilesystemDriver{
+			Name:   "qiniu",
+			Config: map[string]any{"bucket": "test"},
+		})
+		for _, field := range []string{"access_key", "access_secret", "domain"} {
+			if err == nil || !strings.Contains(err.Error(), field) {
+				t.Errorf("错误信息应包含 %s：%v", field, err)
+			}
+		}
+		if err != nil && strings.Contains(err.Error(), "bucket") {
+			t.Errorf("bucket 已配置，不应报告：%v", err)
+		}
+	})
+
+	t.Run("字段类型错误", func(t *testing.T) {
+		_, err := filesystem.NewStorageWithError(config.FilesystemDriver{
+			Name:   "local",
+			Config: map[string]any{"root": []string{"a", "b"}},
+		})
+		if err == nil {
+			t.Error("期望返回类型错误")
+		}
+	})
+
+	t.Run("本地文件权限", func(t *testing.T) {
+		_, err := filesystem.NewStorageWithError(config.FilesystemDriver{
+			Name:   "local",
+			Config: config.LocalDriverConfig{FileMode: "0648", DirMode: "1777"},
+		})
+		for _, field := range []string{"file_mode", "dir_mode"} {
+			if err == nil || !strings.Contains(err.Error(), field) {
+				t.Errorf("错误信息应包含 %s：%v", field, err)
+			}
+		}
+	})
+
+	t.Run("本地签名密钥", func(t *testing.T) {
+		_, err := filesystem.NewStorageWithError(config.FilesystemDriver{
+			Name:   "local",
+			Config: config.LocalDriverConfig{SignKey: "key"},
+		})
+		if !errors.Is(err, config.ErrRequired) || !strings.Contains(err.Error(), "base_url") {
+			t.Errorf("期望返回 base_url 必填错误，实际：%v", err)
+		}
+	})
+
+	t.Run("Webdav地址", func(t *testing.T) {
+		_, err := filesystem.NewStorageWithError(config.FilesystemDriver{
+			Name:   "webdav",
+			Config: config.WebdavDriverConfig{Uri: "ftp://example.com"},
+		})
+		if err == nil || !strings.Contains(err.Error(), "scheme") {
+			t.Errorf("期望返回协议错误，实际：%v", err)
+		}
+	})
+
+	t.Run("Webdav签名密钥", func(t *testing.T) {
+		_, err := filesystem.NewStorageWithError(config.FilesystemDriver{
+			Name:   "webdav",
+			Config: config.WebdavDriverConfig{Uri: "http://example.com", SignKey: "key"},
+		})
+		if !errors.Is(err, config.ErrRequired) || !strings.Contains(err.Error(), "proxy_url") {
+			t.Errorf("期望返回 proxy_url 必填错误，实际：%v", err)
+		}
+	})
+
+	t.Run("SFTP认证信息", func(t *testing.T) {
+		_, err := filesystem.NewStorageWithError(config.FilesystemDriver{
+			Name:   "sftp",
+			Config: config.SftpDriverConfig{Host: "example.com", Username: "user"},
+		})
+		for _, field := range []string{"password or private_key", "host_key"} {
+			if err == nil || !strings.Contains(err.Error(), field) {
+				t.Errorf("错误信息应包含 %s：%v", field, err)
+			}
+		}
+	})
+
+	t.Run("有效配置", func(t *testing.T) {
+		fs, err := filesystem.NewStorageWithError(config.FilesystemDriver{
+			Name:   "local",
+			Config: config.LocalDriverConfig{Root: t.TempDir(), FileMode: "0640", SyncDir: true},
+		})
+		if err != nil || fs == nil {
+			t.Errorf("期望创建成功，实际：%v", err)
+		}
+
+		_, err = filesystem.NewStorageWithError(config.FilesystemDriver{Name: "memory"})
+		if err != nil {
+			t.Errorf("未提供配置时应使用默认值，实际：%v", err)
+		}
+	})
+}
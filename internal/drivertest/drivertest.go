@@ -0,0 +1,242 @@
+// Package drivertest 各驱动共用的测试工具
+// Run 检查驱动是否符合 Filesystem 接口的约定，驱动自己的测试只需要覆盖特有的行为
+package drivertest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	filesystem "github.com/yu1ec/go-filesystem"
+	"github.com/yu1ec/go-filesystem/types"
+)
+
+// Run 对空的可写文件系统运行各驱动共同的测试
+func Run(t *testing.T, fsys filesystem.Filesystem) {
+	ctx := context.Background()
+
+	t.Run("Put和Get", func(t *testing.T) {
+		data := []byte("测试数据")
+		if err := fsys.Put(ctx, "/test.txt", data); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+
+		retrieved, err := fsys.Get("test.txt")
+		if err != nil {
+			t.Fatalf("Get失败：%v", err)
+		}
+		if string(retrieved) != string(data) {
+			t.Errorf("获取的数据不匹配。期望：%s，实际：%s", string(data), string(retrieved))
+		}
+	})
+
+	t.Run("PutStream和GetStream", func(t *testing.T) {
+		data := bytes.Repeat([]byte("0123456789"), 350)
+		// 使用不带长度的流，驱动不能依赖 size
+		if err := fsys.PutStream(ctx, "stream.bin", io.MultiReader(bytes.NewReader(data)), -1); err != nil {
+			t.Fatalf("PutStream失败：%v", err)
+		}
+
+		rc, err := fsys.GetStream(ctx, "stream.bin")
+		if err != nil {
+			t.Fatalf("GetStream失败：%v", err)
+		}
+		defer rc.Close()
+		retrieved, err := io.ReadAll(rc)
+		if err != nil || !bytes.Equal(retrieved, data) {
+			t.Errorf("读取的数据不匹配，长度：%d，错误：%v", len(retrieved), err)
+		}
+	})
+
+	if rr, ok := fsys.(filesystem.RangeReader); ok {
+		t.Run("GetStreamRange", func(t *testing.T) {
+			RunRangeReader(t, fsys, rr)
+		})
+	}
+
+	t.Run("GetImageWidthHeight", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 100, 50))); err != nil {
+			t.Fatalf("无法编码图片：%v", err)
+		}
+		if err := fsys.Put(ctx, "image.png", buf.Bytes()); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+
+		width, height, err := fsys.GetImageWidthHeight("image.png")
+		if err != nil {
+			t.Fatalf("GetImageWidthHeight失败：%v", err)
+		}
+		if width != 100 || height != 50 {
+			t.Errorf("图片尺寸不匹配。期望：100x50，实际：%dx%d", width, height)
+		}
+	})
+
+	t.Run("Stat", func(t *testing.T) {
+		info, err := fsys.Stat(ctx, "image.png")
+		if err != nil {
+			t.Fatalf("Stat失败：%v", err)
+		}
+		if info.ContentType != "image/png" || info.Size == 0 || info.ETag == "" || info.LastModified.IsZero() {
+			t.Errorf("文件信息不正确：%+v", info)
+		}
+
+		_, err = fsys.Stat(ctx, "missing.txt")
+		var pathErr *types.PathError
+		if !errors.Is(err, types.ErrNotFound) || !errors.As(err, &pathErr) {
+			t.Errorf("期望 PathError 且为 ErrNotFound，实际：%v", err)
+		}
+		if _, err := fsys.Get("missing.txt"); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("期望 ErrNotFound，实际：%v", err)
+		}
+	})
+
+	t.Run("Exists和Delete", func(t *testing.T) {
+		if err := fsys.Put(ctx, "delete.txt", []byte("x")); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+		if exists, err := fsys.ExistsE(ctx, "delete.txt"); !exists || err != nil {
+			t.Fatalf("文件应该存在：%v", err)
+		}
+		if err := fsys.Delete("delete.txt"); err != nil {
+			t.Fatalf("Delete失败：%v", err)
+		}
+		if exists, err := fsys.ExistsE(ctx, "delete.txt"); exists || err != nil {
+			t.Errorf("文件应该已被删除：%v", err)
+		}
+		if err := fsys.Delete("delete.txt"); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("删除不存在的文件期望 ErrNotFound，实际：%v", err)
+		}
+	})
+
+	t.Run("Copy和Move", func(t *testing.T) {
+		if err := fsys.Put(ctx, "src/a.txt", []byte("源文件")); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+		if err := fsys.Copy(ctx, "src/a.txt", "dst/a.txt", false); err != nil {
+			t.Fatalf("Copy失败：%v", err)
+		}
+		if err := fsys.Copy(ctx, "src/a.txt", "dst/a.txt", false); !errors.Is(err, types.ErrAlreadyExists) {
+			t.Errorf("期望 ErrAlreadyExists，实际：%v", err)
+		}
+		if data, _ := fsys.Get("dst/a.txt"); string(data) != "源文件" {
+			t.Errorf("复制后的内容不匹配：%s", string(data))
+		}
+
+		if err := fsys.Move(ctx, "src/a.txt", "dst/b.txt", false); err != nil {
+			t.Fatalf("Move失败：%v", err)
+		}
+		if fsys.Exists("src/a.txt") {
+			t.Error("移动后源文件应不存在")
+		}
+		if data, _ := fsys.Get("dst/b.txt"); string(data) != "源文件" {
+			t.Errorf("移动后的内容不匹配：%s", string(data))
+		}
+		if err := fsys.Move(ctx, "src/a.txt", "dst/c.txt", false); !errors.Is(err, types.ErrNotFound) {
+			t.Errorf("期望 ErrNotFound，实际：%v", err)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		for _, p := range []string{"list/a.txt", "list/b.txt", "list/sub/c.txt", "list/sub/deep/d.txt", "list.txt"} {
+			if err := fsys.Put(ctx, p, []byte(p)); err != nil {
+				t.Fatalf("Put失败：%v", err)
+			}
+		}
+
+		t.Run("非递归", func(t *testing.T) {
+			result, err := fsys.List(ctx, "list", types.ListOptions{})
+			if err != nil {
+				t.Fatalf("List失败：%v", err)
+			}
+			// 目录和文件的先后顺序由驱动决定
+			files := append([]types.FileInfo(nil), result.Files...)
+			sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+			expected := "list/a.txt,list/b.txt,list/sub/"
+			if got := ListPaths(files); got != expected {
+				t.Fatalf("期望 %s，实际 %s", expected, got)
+			}
+			if !files[2].IsDir {
+				t.Error("list/sub/ 应该是目录")
+			}
+		})
+
+		t.Run("递归分页", func(t *testing.T) {
+			var all []types.FileInfo
+			pages := 0
+			opts := types.ListOptions{Recursive: true, Limit: 3}
+			for {
+				result, err := fsys.List(ctx, "list/", opts)
+				if err != nil {
+					t.Fatalf("List失败：%v", err)
+				}
+				all = append(all, result.Files...)
+				pages++
+				if result.NextCursor == "" {
+					break
+				}
+				opts.Cursor = result.NextCursor
+			}
+
+			expected := "list/a.txt,list/b.txt,list/sub/c.txt,list/sub/deep/d.txt"
+			if got := ListPaths(all); got != expected || pages != 2 {
+				t.Errorf("期望分2页返回 %s，实际%d页 %s", expected, pages, got)
+			}
+		})
+
+		t.Run("目录不存在", func(t *testing.T) {
+			result, err := fsys.List(ctx, "missing", types.ListOptions{})
+			if err != nil || len(result.Files) != 0 {
+				t.Errorf("目录不存在时应返回空列表：%v %v", result.Files, err)
+			}
+		})
+	})
+}
+
+// RunRangeReader 检查按范围读取，fsys 和 rr 为同一个驱动
+func RunRangeReader(t *testing.T, fsys filesystem.Filesystem, rr filesystem.RangeReader) {
+	ctx := context.Background()
+	if err := fsys.Put(ctx, "range.txt", []byte("0123456789")); err != nil {
+		t.Fatalf("Put失败：%v", err)
+	}
+
+	cases := []struct {
+		offset, length int64
+		expected       string
+	}{
+		{0, 3, "012"},
+		{2, 4, "2345"},
+		{7, -1, "789"},
+		{7, 0, "789"},
+	}
+	for _, c := range cases {
+		rc, err := rr.GetStreamRange(ctx, "range.txt", c.offset, c.length)
+		if err != nil {
+			t.Fatalf("GetStreamRange(%d, %d)失败：%v", c.offset, c.length, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil || string(data) != c.expected {
+			t.Errorf("GetStreamRange(%d, %d) 期望 %s，实际 %s %v", c.offset, c.length, c.expected, data, err)
+		}
+	}
+
+	if _, err := rr.GetStreamRange(ctx, "missing.txt", 1, 1); !errors.Is(err, types.ErrNotFound) {
+		t.Errorf("期望 ErrNotFound，实际：%v", err)
+	}
+}
+
+// ListPaths 将文件列表的路径用逗号连接，方便比较
+func ListPaths(files []types.FileInfo) string {
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path
+	}
+	return strings.Join(paths, ",")
+}
@@ -0,0 +1,90 @@
+package drivertest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Object 模拟对象存储中的对象，供各驱动的模拟服务使用
+type Object struct {
+	Data        []byte
+	ContentType string
+	ModTime     time.Time
+}
+
+// NewObject 创建修改时间为当前时间的对象
+func NewObject(data []byte, contentType string) Object {
+	return Object{Data: data, ContentType: contentType, ModTime: time.Now()}
+}
+
+// ETag 返回带引号的内容MD5
+func (o Object) ETag() string {
+	sum := md5.Sum(o.Data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// Serve 返回对象内容，由 http.ServeContent 处理 HEAD、Range 和条件请求
+func (o Object) Serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", o.ContentType)
+	w.Header().Set("ETag", o.ETag())
+	http.ServeContent(w, r, "", o.ModTime, bytes.NewReader(o.Data))
+}
+
+// Objects 模拟的存储桶，key 为对象名称
+type Objects map[string]Object
+
+// List 按名称顺序列举 prefix 下位于 marker 之后的对象
+// delimiter 不为空时同一目录下的对象合并为公共前缀，对象和公共前缀合计最多返回 max 项
+// 还有下一页时 next 为本页最后一项，作为下一页的 marker
+func (objs Objects) List(prefix, delimiter, marker string, max int) (keys, prefixes []string, next string) {
+	names := make([]string, 0, len(objs))
+	for name := range objs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	count, last := 0, marker
+	for _, name := range names {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		entry := name
+		if i := strings.Index(name[len(prefix):], delimiter); delimiter != "" && i >= 0 {
+			entry = name[:len(prefix)+i+1]
+		}
+		if entry <= last {
+			continue
+		}
+		if count == max {
+			return keys, prefixes, last
+		}
+
+		if entry != name {
+			prefixes = append(prefixes, entry)
+		} else {
+			keys = append(keys, name)
+		}
+		count, last = count+1, entry
+	}
+	return keys, prefixes, ""
+}
+
+// WriteXML 以XML格式写入响应
+func WriteXML(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(v)
+}
+
+// WriteXMLError 写入S3风格的XML错误响应
+func WriteXMLError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
@@ -0,0 +1,31 @@
+// Package fsutil 文件类驱动（local、sftp、ftp、archive）共用的辅助函数
+package fsutil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ContextReader 返回每次读取前检查 ctx 是否已取消的 Reader，用于中断大文件的传输
+func ContextReader(ctx context.Context, r io.Reader) io.Reader {
+	return &contextReader{ctx: ctx, r: r}
+}
+
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// ETag 由修改时间和大小生成 ETag，用于无法获取内容摘要的驱动
+func ETag(modTime time.Time, size int64) string {
+	return fmt.Sprintf(`"%x-%x"`, modTime.Unix(), size)
+}
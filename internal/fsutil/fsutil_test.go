@@ -0,0 +1,37 @@
+package fsutil_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yu1ec/go-filesystem/internal/fsutil"
+)
+
+func TestContextReader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := fsutil.ContextReader(ctx, strings.NewReader("0123456789"))
+
+	buf := make([]byte, 4)
+	if n, err := r.Read(buf); n != 4 || err != nil {
+		t.Fatalf("读取失败：%d %v", n, err)
+	}
+
+	cancel()
+	if _, err := io.ReadAll(r); !errors.Is(err, context.Canceled) {
+		t.Errorf("取消后期望 context.Canceled，实际：%v", err)
+	}
+}
+
+func TestETag(t *testing.T) {
+	modTime := time.Unix(0x5f5e100, 0)
+	if etag := fsutil.ETag(modTime, 255); etag != `"5f5e100-ff"` {
+		t.Errorf("ETag不正确：%s", etag)
+	}
+	if fsutil.ETag(modTime, 255) == fsutil.ETag(modTime.Add(time.Second), 255) {
+		t.Error("修改时间不同时ETag应该不同")
+	}
+}
@@ -0,0 +1,119 @@
+// Package objstore 对象存储驱动共用的上传和路径处理
+package objstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	pathpkg "path"
+	"strings"
+)
+
+// DefaultPartSize 默认分片大小，S3 等要求除最后一片外每片不小于 5MB
+const DefaultPartSize = 8 << 20
+
+// Uploader 驱动提供的上传接口
+type Uploader interface {
+	// PutObject 一次上传全部数据，数据不超过一个分片时使用
+	PutObject(ctx context.Context, key, contentType string, data []byte) error
+	// CreateMultipart 开始分片上传
+	CreateMultipart(ctx context.Context, key, contentType string) (Multipart, error)
+}
+
+// Multipart 进行中的分片上传
+type Multipart interface {
+	// UploadPart 上传一个分片，partNumber 从1开始，data 在返回后会被复用
+	UploadPart(ctx context.Context, partNumber int, data []byte) error
+	// Complete 按顺序合并已上传的分片
+	Complete(ctx context.Context) error
+	// Abort 取消上传，释放已上传的分片
+	Abort(ctx context.Context) error
+}
+
+// PutStream 以流的方式上传对象
+// 数据不超过分片大小时直接上传，否则使用分片上传，内存占用不超过一个分片
+// size 为数据长度，已知且小于分片大小时只分配 size 大小的缓冲区，未知时传 -1
+// partSize 小于等于0时使用 DefaultPartSize
+func PutStream(ctx context.Context, u Uploader, key string, reader io.Reader, size, partSize int64) error {
+	partSize = PartSize(partSize)
+	bufSize := partSize
+	if size >= 0 && size < partSize {
+		bufSize = size
+	}
+
+	buf := make([]byte, bufSize)
+	n, err := io.ReadFull(reader, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+
+	contentType := mime.TypeByExtension(pathpkg.Ext(key))
+	if contentType == "" {
+		contentType = http.DetectContentType(buf[:n])
+	}
+
+	if int64(n) < partSize || int64(n) == size {
+		return u.PutObject(ctx, key, contentType, buf[:n])
+	}
+
+	m, err := u.CreateMultipart(ctx, key, contentType)
+	if err != nil {
+		return err
+	}
+	if err := uploadParts(ctx, m, buf, reader); err != nil {
+		// ctx 可能已经取消，取消上传使用独立的上下文
+		m.Abort(context.WithoutCancel(ctx))
+		return err
+	}
+	return nil
+}
+
+// uploadParts 上传全部分片后合并，buf 中为已读取的第一个分片
+func uploadParts(ctx context.Context, m Multipart, buf []byte, reader io.Reader) error {
+	n := len(buf)
+	for partNumber := 1; n > 0; partNumber++ {
+		if err := m.UploadPart(ctx, partNumber, buf[:n]); err != nil {
+			return err
+		}
+
+		var err error
+		n, err = io.ReadFull(reader, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+	}
+	return m.Complete(ctx)
+}
+
+// PartSize 返回实际使用的分片大小，size 小于等于0时使用 DefaultPartSize
+func PartSize(size int64) int64 {
+	if size > 0 {
+		return size
+	}
+	return DefaultPartSize
+}
+
+// RangeHeader 返回读取 offset 开始 length 字节的 Range 请求头，length 小于等于0时读取到末尾
+func RangeHeader(offset, length int64) string {
+	if length <= 0 {
+		return fmt.Sprintf("bytes=%d-", offset)
+	}
+	return fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+}
+
+// Key 将文件路径转换为对象的key，对象存储的key不以 / 开头
+func Key(path string) string {
+	return strings.TrimLeft(path, "/")
+}
+
+// EscapeKey 对key的每一段进行URL编码，保留分隔符 /
+func EscapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,110 @@
+package objstore_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yu1ec/go-filesystem/internal/objstore"
+)
+
+type fakeUploader struct {
+	put       []byte
+	putCap    int
+	parts     [][]byte
+	failPart  int
+	completed bool
+	aborted   bool
+}
+
+func (u *fakeUploader) PutObject(ctx context.Context, key, contentType string, data []byte) error {
+	u.put = append([]byte(nil), data...)
+	u.putCap = cap(data)
+	return nil
+}
+
+func (u *fakeUploader) CreateMultipart(ctx context.Context, key, contentType string) (objstore.Multipart, error) {
+	return u, nil
+}
+
+func (u *fakeUploader) UploadPart(ctx context.Context, partNumber int, data []byte) error {
+	if partNumber == u.failPart {
+		return errors.New("upload part failed")
+	}
+	u.parts = append(u.parts, append([]byte(nil), data...))
+	return nil
+}
+
+func (u *fakeUploader) Complete(ctx context.Context) error {
+	u.completed = true
+	return nil
+}
+
+func (u *fakeUploader) Abort(ctx context.Context) error {
+	u.aborted = true
+	return nil
+}
+
+func TestPutStream(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("已知大小的小文件按实际大小分配缓冲区", func(t *testing.T) {
+		u := &fakeUploader{}
+		if err := objstore.PutStream(ctx, u, "a.txt", strings.NewReader("hello"), 5, 0); err != nil {
+			t.Fatalf("上传失败: %v", err)
+		}
+		if string(u.put) != "hello" || u.putCap != 5 {
+			t.Errorf("期望直接上传5字节，实际 %q cap=%d", u.put, u.putCap)
+		}
+	})
+
+	t.Run("大小未知时分片上传", func(t *testing.T) {
+		u := &fakeUploader{}
+		if err := objstore.PutStream(ctx, u, "a.bin", strings.NewReader("0123456789"), -1, 4); err != nil {
+			t.Fatalf("上传失败: %v", err)
+		}
+		if len(u.parts) != 3 || !u.completed {
+			t.Fatalf("期望3个分片并合并，实际 %d completed=%v", len(u.parts), u.completed)
+		}
+		if got := bytes.Join(u.parts, nil); string(got) != "0123456789" {
+			t.Errorf("分片内容不一致: %q", got)
+		}
+	})
+
+	t.Run("大小等于分片大小时直接上传", func(t *testing.T) {
+		u := &fakeUploader{}
+		if err := objstore.PutStream(ctx, u, "a.bin", strings.NewReader("0123"), 4, 4); err != nil {
+			t.Fatalf("上传失败: %v", err)
+		}
+		if string(u.put) != "0123" || len(u.parts) != 0 {
+			t.Errorf("期望直接上传，实际 put=%q parts=%d", u.put, len(u.parts))
+		}
+	})
+
+	t.Run("分片失败时取消上传", func(t *testing.T) {
+		u := &fakeUploader{failPart: 2}
+		if err := objstore.PutStream(ctx, u, "a.bin", strings.NewReader("0123456789"), -1, 4); err == nil {
+			t.Fatal("分片失败应返回错误")
+		}
+		if !u.aborted || u.completed {
+			t.Errorf("期望取消上传，实际 aborted=%v completed=%v", u.aborted, u.completed)
+		}
+	})
+}
+
+func TestEscapeKey(t *testing.T) {
+	if got := objstore.EscapeKey(objstore.Key("/dir/a b?.txt")); got != "dir/a%20b%3F.txt" {
+		t.Errorf("编码结果不正确: %s", got)
+	}
+}
+
+func TestRangeHeader(t *testing.T) {
+	if got := objstore.RangeHeader(2, 4); got != "bytes=2-5" {
+		t.Errorf("范围不正确: %s", got)
+	}
+	if got := objstore.RangeHeader(2, -1); got != "bytes=2-" {
+		t.Errorf("读取到末尾的范围不正确: %s", got)
+	}
+}
@@ -0,0 +1,166 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPattern 匹配 ${VAR}、${VAR:-default} 以及转义用的 $${
+var envPattern = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// secretFileSuffix 以此结尾的字段从文件读取对应字段的值，如 access_secret_file 读取后写入 access_secret
+const secretFileSuffix = "_file"
+
+// resolveConfig 解析配置中的环境变量和密钥文件
+// 配置值中的 ${VAR} 替换为环境变量，变量未设置时返回错误，${VAR:-default} 在变量未设置或为空时使用默认值，$${ 表示字面的 ${
+// 驱动配置中不存在的 xxx_file 字段会读取文件内容作为 xxx 字段的值，文件末尾的换行会被去掉
+func resolveConfig(data []byte, output any) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse driver config: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return data, nil
+	}
+
+	root := doc.Content[0]
+	fields := yamlFields(output)
+	err := errors.Join(
+		expandEnv(root, fields),
+		loadSecretFiles(root, fields),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(&doc)
+}
+
+// expandEnv 递归替换所有字符串值中的环境变量
+// fields 为 node 对应的驱动配置字段及其类型，嵌套的节点没有对应的字段
+func expandEnv(node *yaml.Node, fields map[string]reflect.Kind) error {
+	if node.Kind != yaml.MappingNode {
+		return expandValue(node, reflect.Invalid)
+	}
+
+	var errs []error
+	// 不替换字段名
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		errs = append(errs, expandValue(node.Content[i+1], fields[node.Content[i].Value]))
+	}
+	return errors.Join(errs...)
+}
+
+// expandValue 替换值中的环境变量，kind 为目标字段的类型，未知时为 reflect.Invalid
+func expandValue(node *yaml.Node, kind reflect.Kind) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return expandEnv(node, nil)
+	case yaml.SequenceNode:
+		var errs []error
+		for _, child := range node.Content {
+			errs = append(errs, expandValue(child, reflect.Invalid))
+		}
+		return errors.Join(errs...)
+	case yaml.ScalarNode:
+	default:
+		return nil
+	}
+
+	if !strings.Contains(node.Value, "${") {
+		return nil
+	}
+
+	var errs []error
+	node.Value = envPattern.ReplaceAllStringFunc(node.Value, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+
+		sub := envPattern.FindStringSubmatch(match)
+		name, hasDefault := sub[1], strings.Contains(match, ":-")
+		value, ok := os.LookupEnv(name)
+		switch {
+		case hasDefault && value == "":
+			return sub[2]
+		case !ok:
+			errs = append(errs, fmt.Errorf("environment variable %s is not set", name))
+		}
+		return value
+	})
+	// 数字和布尔等字段替换后重新推断类型，使 ${PORT}、${PRIVATE} 可以使用
+	// 其他字段保持为字符串，避免 null、~、true、0x10 等值改变类型
+	if kind == reflect.Invalid || kind == reflect.String {
+		node.Tag = "!!str"
+	} else {
+		node.Tag = ""
+	}
+	return errors.Join(errs...)
+}
+
+// loadSecretFiles 读取 xxx_file 字段指定的文件，替换为 xxx 字段
+// fields 为驱动配置中已声明的字段，已声明的 xxx_file 字段保持原样
+func loadSecretFiles(node *yaml.Node, fields map[string]reflect.Kind) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	keys := make(map[string]bool)
+	for i := 0; i < len(node.Content); i += 2 {
+		keys[node.Content[i].Value] = true
+	}
+
+	var errs []error
+	for i := 0; i < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if _, ok := fields[key.Value]; !strings.HasSuffix(key.Value, secretFileSuffix) || ok {
+			continue
+		}
+
+		field := strings.TrimSuffix(key.Value, secretFileSuffix)
+		if keys[field] {
+			errs = append(errs, fmt.Errorf("%s and %s cannot be set at the same time", field, key.Value))
+			continue
+		}
+
+		secret, err := os.ReadFile(value.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key.Value, err))
+			continue
+		}
+
+		key.Value = field
+		node.Content[i+1] = &yaml.Node{
+			Kind:  yaml.ScalarNode,
+			Tag:   "!!str",
+			Value: strings.TrimRight(string(secret), "\r\n"),
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// yamlFields 获取结构体的 YAML 字段名和字段类型
+func yamlFields(output any) map[string]reflect.Kind {
+	t := reflect.TypeOf(output)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := make(map[string]reflect.Kind)
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name == "" {
+			name = strings.ToLower(t.Field(i).Name)
+		}
+		fields[name] = t.Field(i).Type.Kind()
+	}
+	return fields
+}
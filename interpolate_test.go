@@ -0,0 +1,128 @@
+package filesystem_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yu1ec/go-filesystem"
+	"github.com/yu1ec/go-filesystem/config"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDecodeConfig_Env(t *testing.T) {
+	t.Setenv("TEST_QINIU_AK", "ak")
+	t.Setenv("TEST_QINIU_SK", "sk$1")
+	t.Setenv("TEST_QINIU_PRIVATE", "true")
+
+	var input map[string]any
+	err := yaml.Unmarshal([]byte(`
+access_key: ${TEST_QINIU_AK}
+access_secret: ${TEST_QINIU_SK}
+bucket: ${TEST_QINIU_BUCKET:-default-bucket}
+domain: https://${TEST_QINIU_DOMAIN:-cdn.example.com}/$${literal}
+private: ${TEST_QINIU_PRIVATE}
+`), &input)
+	if err != nil {
+		t.Fatalf("无法解析YAML：%v", err)
+	}
+
+	var cfg config.QiniuDriverConfig
+	if err := filesystem.DecodeConfig(input, &cfg); err != nil {
+		t.Fatalf("DecodeConfig失败：%v", err)
+	}
+
+	expected := config.QiniuDriverConfig{
+		AccessKey:    "ak",
+		AccessSecret: "sk$1",
+		Bucket:       "default-bucket",
+		Domain:       "https://cdn.example.com/${literal}",
+		Private:      true,
+	}
+	if cfg != expected {
+		t.Errorf("配置不匹配。期望：%+v，实际：%+v", expected, cfg)
+	}
+
+	t.Run("字符串字段保持类型", func(t *testing.T) {
+		t.Setenv("TEST_FTP_HOST", "0x10")
+		t.Setenv("TEST_FTP_PORT", "2121")
+		t.Setenv("TEST_FTP_USERNAME", "null")
+		t.Setenv("TEST_FTP_PASSWORD", "~")
+		t.Setenv("TEST_FTP_ROOT", "true")
+
+		var cfg config.FtpDriverConfig
+		err := filesystem.DecodeConfig(map[string]any{
+			"host":     "${TEST_FTP_HOST}",
+			"port":     "${TEST_FTP_PORT}",
+			"username": "${TEST_FTP_USERNAME}",
+			"password": "${TEST_FTP_PASSWORD}",
+			"root":     "${TEST_FTP_ROOT}",
+		}, &cfg)
+		if err != nil {
+			t.Fatalf("DecodeConfig失败：%v", err)
+		}
+
+		expected := config.FtpDriverConfig{Host: "0x10", Port: 2121, Username: "null", Password: "~", Root: "true"}
+		if cfg != expected {
+			t.Errorf("配置不匹配。期望：%+v，实际：%+v", expected, cfg)
+		}
+	})
+
+	t.Run("环境变量未设置", func(t *testing.T) {
+		var cfg config.WebdavDriverConfig
+		err := filesystem.DecodeConfig(map[string]any{
+			"uri":      "http://example.com",
+			"password": "${TEST_WEBDAV_MISSING}",
+		}, &cfg)
+		if err == nil || !strings.Contains(err.Error(), "TEST_WEBDAV_MISSING") {
+			t.Errorf("期望返回环境变量未设置的错误，实际：%v", err)
+		}
+	})
+}
+
+func TestDecodeConfig_SecretFile(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(secretFile, []byte("p@ss\n"), 0600); err != nil {
+		t.Fatalf("无法写入密钥文件：%v", err)
+	}
+	t.Setenv("TEST_SECRET_DIR", dir)
+
+	var cfg config.WebdavDriverConfig
+	err := filesystem.DecodeConfig(map[string]any{
+		"uri":           "http://example.com",
+		"username":      "user",
+		"password_file": "${TEST_SECRET_DIR}/password",
+	}, &cfg)
+	if err != nil {
+		t.Fatalf("DecodeConfig失败：%v", err)
+	}
+	if cfg.Password != "p@ss" {
+		t.Errorf("密码不匹配：%q", cfg.Password)
+	}
+
+	t.Run("同时设置", func(t *testing.T) {
+		var cfg config.WebdavDriverConfig
+		err := filesystem.DecodeConfig(map[string]any{
+			"uri":           "http://example.com",
+			"password":      "plain",
+			"password_file": secretFile,
+		}, &cfg)
+		if err == nil || !strings.Contains(err.Error(), "cannot be set at the same time") {
+			t.Errorf("期望返回冲突错误，实际：%v", err)
+		}
+	})
+
+	t.Run("文件不存在", func(t *testing.T) {
+		var cfg config.WebdavDriverConfig
+		err := filesystem.DecodeConfig(map[string]any{
+			"uri":           "http://example.com",
+			"password_file": filepath.Join(dir, "missing"),
+		}, &cfg)
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("期望返回文件不存在的错误，实际：%v", err)
+		}
+	})
+}
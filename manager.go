@@ -0,0 +1,182 @@
+package filesystem
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/yu1ec/go-filesystem/config"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnknownDisk   = errors.New("unknown disk")      // 文件系统名称未配置
+	ErrManagerClosed = errors.New("manager is closed") // Manager 已关闭
+)
+
+// Manager 管理多个命名的文件系统
+// 文件系统在第一次使用时才创建，创建成功后复用，创建失败时下次使用会重试
+// 创建时不持有锁，连接较慢的文件系统不会阻塞其他名称，同一名称的并发请求共用一次创建
+type Manager struct {
+	cfg config.FilesystemConfig
+
+	mu       sync.Mutex
+	disks    map[string]Filesystem
+	creating map[string]*creation
+	closed   bool
+}
+
+// creation 正在创建的文件系统，创建完成后关闭 done
+type creation struct {
+	done chan struct{}
+	fs   Filesystem
+	err  error
+}
+
+// NewManager 根据配置创建文件系统管理器
+// 配置了 Default 但 Disks 中不存在该名称时返回错误
+func NewManager(cfg config.FilesystemConfig) (*Manager, error) {
+	if cfg.Default != "" {
+		if _, ok := cfg.Disks[cfg.Default]; !ok {
+			return nil, fmt.Errorf("default disk %q: %w", cfg.Default, ErrUnknownDisk)
+		}
+	}
+
+	return &Manager{
+		cfg:      cfg,
+		disks:    make(map[string]Filesystem),
+		creating: make(map[string]*creation),
+	}, nil
+}
+
+// NewManagerFromYAML 从YAML创建文件系统管理器，出现未知字段时返回错误
+func NewManagerFromYAML(data []byte) (*Manager, error) {
+	var cfg config.FilesystemConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode filesystem config: %w", err)
+	}
+	return NewManager(cfg)
+}
+
+// Disk 获取指定名称的文件系统
+func (m *Manager) Disk(name string) (Filesystem, error) {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil, ErrManagerClosed
+	}
+	if fs, ok := m.disks[name]; ok {
+		m.mu.Unlock()
+		return fs, nil
+	}
+	driver, ok := m.cfg.Disks[name]
+	if !ok {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("disk %q: %w", name, ErrUnknownDisk)
+	}
+
+	c, ok := m.creating[name]
+	if ok {
+		m.mu.Unlock()
+		<-c.done
+	} else {
+		c = &creation{done: make(chan struct{})}
+		m.creating[name] = c
+		m.mu.Unlock()
+
+		c.fs, c.err = NewStorageWithError(driver)
+		m.store(name, c)
+		close(c.done)
+	}
+
+	if c.err != nil {
+		if errors.Is(c.err, ErrManagerClosed) {
+			return nil, c.err
+		}
+		return nil, fmt.Errorf("disk %q: %w", name, c.err)
+	}
+	return c.fs, nil
+}
+
+// store 保存创建的文件系统，创建期间 Manager 已关闭时关闭该文件系统
+func (m *Manager) store(name string, c *creation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.creating, name)
+	if c.err != nil {
+		return
+	}
+	if m.closed {
+		if closer, ok := c.fs.(io.Closer); ok {
+			closer.Close()
+		}
+		c.fs, c.err = nil, ErrManagerClosed
+		return
+	}
+	m.disks[name] = c.fs
+}
+
+// Close 关闭已创建的文件系统中实现了 io.Closer 的部分，如 SFTP、FTP 的连接
+// 关闭后 Disk 返回 ErrManagerClosed
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+
+	var errs []error
+	for name, fs := range m.disks {
+		if closer, ok := fs.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("disk %q: %w", name, err))
+			}
+		}
+	}
+	m.disks = nil
+	return errors.Join(errs...)
+}
+
+// Default 获取默认文件系统
+func (m *Manager) Default() (Filesystem, error) {
+	if m.cfg.Default == "" {
+		return nil, fmt.Errorf("default disk is not configured: %w", ErrUnknownDisk)
+	}
+	return m.Disk(m.cfg.Default)
+}
+
+// MustDisk 获取指定名称的文件系统，失败时 panic
+func (m *Manager) MustDisk(name string) Filesystem {
+	fs, err := m.Disk(name)
+	if err != nil {
+		panic(err)
+	}
+	return fs
+}
+
+// MustDefault 获取默认文件系统，失败时 panic
+func (m *Manager) MustDefault() Filesystem {
+	fs, err := m.Default()
+	if err != nil {
+		panic(err)
+	}
+	return fs
+}
+
+// Names 返回已配置的文件系统名称，按字母排序
+func (m *Manager) Names() []string {
+	names := make([]string, 0, len(m.cfg.Disks))
+	for name := range m.cfg.Disks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,183 @@
+package filesystem_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/yu1ec/go-filesystem"
+	"github.com/yu1ec/go-filesystem/config"
+	"github.com/yu1ec/go-filesystem/driver/memory"
+)
+
+func TestManager(t *testing.T) {
+	manager, err := filesystem.NewManagerFromYAML([]byte(`
+default: public
+disks:
+  public:
+    name: memory
+    config:
+      base_url: https://cdn.example.com
+  scratch:
+    name: local
+    config:
+      root: ` + t.TempDir() + `
+  broken:
+    name: qiniu
+    config:
+      bucket: test
+`))
+	if err != nil {
+		t.Fatalf("NewManagerFromYAML失败：%v", err)
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		fs, err := manager.Default()
+		if err != nil {
+			t.Fatalf("Default失败：%v", err)
+		}
+		if url := fs.GetUrl("a.txt"); url != "https://cdn.example.com/a.txt" {
+			t.Errorf("默认文件系统不正确，URL：%s", url)
+		}
+	})
+
+	t.Run("复用已创建的文件系统", func(t *testing.T) {
+		fs := manager.MustDisk("public")
+		if err := fs.Put(context.Background(), "a.txt", []byte("data")); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+		if !manager.MustDefault().Exists("a.txt") {
+			t.Error("同一名称应返回同一个文件系统")
+		}
+	})
+
+	t.Run("Disk", func(t *testing.T) {
+		fs, err := manager.Disk("scratch")
+		if err != nil {
+			t.Fatalf("Disk失败：%v", err)
+		}
+		if err := fs.Put(context.Background(), "b.txt", []byte("data")); err != nil {
+			t.Errorf("Put失败：%v", err)
+		}
+	})
+
+	t.Run("错误", func(t *testing.T) {
+		if _, err := manager.Disk("missing"); !errors.Is(err, filesystem.ErrUnknownDisk) {
+			t.Errorf("期望 ErrUnknownDisk，实际：%v", err)
+		}
+		if _, err := manager.Disk("broken"); !errors.Is(err, config.ErrRequired) {
+			t.Errorf("期望配置校验错误，实际：%v", err)
+		}
+	})
+
+	t.Run("Names", func(t *testing.T) {
+		names := manager.Names()
+		if len(names) != 3 || names[0] != "broken" || names[1] != "public" || names[2] != "scratch" {
+			t.Errorf("名称列表不正确：%v", names)
+		}
+	})
+}
+
+func TestNewManager_InvalidDefault(t *testing.T) {
+	_, err := filesystem.NewManager(config.FilesystemConfig{
+		Default: "missing",
+		Disks: map[string]config.FilesystemDriver{
+			"public": {Name: "memory"},
+		},
+	})
+	if !errors.Is(err, filesystem.ErrUnknownDisk) {
+		t.Errorf("期望 ErrUnknownDisk，实际：%v", err)
+	}
+
+	manager, err := filesystem.NewManager(config.FilesystemConfig{})
+	if err != nil {
+		t.Fatalf("NewManager失败：%v", err)
+	}
+	if _, err := manager.Default(); !errors.Is(err, filesystem.ErrUnknownDisk) {
+		t.Errorf("未配置默认文件系统时期望 ErrUnknownDisk，实际：%v", err)
+	}
+}
+
+func TestManager_ConcurrentCreate(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	filesystem.RegisterDriver("manager_slow_test", func(cfg any) (filesystem.Filesystem, error) {
+		calls.Add(1)
+		<-release
+		return memory.NewStorage(""), nil
+	})
+
+	manager, err := filesystem.NewManager(config.FilesystemConfig{
+		Disks: map[string]config.FilesystemDriver{
+			"slow": {Name: "manager_slow_test"},
+			"fast": {Name: "memory"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewManager失败：%v", err)
+	}
+
+	results := make(chan filesystem.Filesystem, 2)
+	for range 2 {
+		go func() {
+			fs, err := manager.Disk("slow")
+			if err != nil {
+				t.Errorf("Disk失败：%v", err)
+			}
+			results <- fs
+		}()
+	}
+
+	// 创建 slow 期间其他名称不受影响
+	if _, err := manager.Disk("fast"); err != nil {
+		t.Fatalf("Disk失败：%v", err)
+	}
+	close(release)
+	if a, b := <-results, <-results; a == nil || a != b {
+		t.Error("并发获取同一名称应返回同一个文件系统")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("期望只创建一次，实际：%d", calls.Load())
+	}
+}
+
+// closerFilesystem 记录 Close 调用
+type closerFilesystem struct {
+	filesystem.Filesystem
+	closed bool
+}
+
+func (fs *closerFilesystem) Close() error {
+	fs.closed = true
+	return nil
+}
+
+func TestManager_Close(t *testing.T) {
+	fs := &closerFilesystem{Filesystem: memory.NewStorage("")}
+	filesystem.RegisterDriver("manager_closer_test", func(cfg any) (filesystem.Filesystem, error) {
+		return fs, nil
+	})
+
+	manager, err := filesystem.NewManager(config.FilesystemConfig{
+		Disks: map[string]config.FilesystemDriver{
+			"closer": {Name: "manager_closer_test"},
+			"memory": {Name: "memory"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewManager失败：%v", err)
+	}
+	manager.MustDisk("closer")
+	manager.MustDisk("memory")
+
+	if err := manager.Close(); err != nil {
+		t.Fatalf("Close失败：%v", err)
+	}
+	if !fs.closed {
+		t.Error("实现了 io.Closer 的文件系统应该被关闭")
+	}
+	if _, err := manager.Disk("memory"); !errors.Is(err, filesystem.ErrManagerClosed) {
+		t.Errorf("关闭后期望 ErrManagerClosed，实际：%v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/yu1ec/go-filesystem/config"
+	"github.com/yu1ec/go-filesystem/driver/local"
+	"github.com/yu1ec/go-filesystem/driver/memory"
+	"github.com/yu1ec/go-filesystem/driver/qiniu"
+	"github.com/yu1ec/go-filesystem/driver/webdav"
+)
+
+// ErrUnknownDriver 驱动名称未注册，非内置驱动需要先导入驱动包
+var ErrUnknownDriver = errors.New("unknown filesystem driver")
+
+// DriverFactory 根据配置创建文件系统
+// cfg 为 config.FilesystemDriver 中的 Config，可以使用 DecodeConfig 解码为驱动自己的配置结构体
+type DriverFactory func(cfg any) (Filesystem, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]DriverFactory)
+)
+
+// 内置 local、memory、qiniu 和 webdav 驱动
+// 其他驱动在各自的包中注册，需要匿名导入，如 import _ "github.com/yu1ec/go-filesystem/driver/s3"
+// 导入 driver/all 可以注册全部驱动
+func init() {
+	RegisterDriver("local", func(cfg any) (Filesystem, error) {
+		var c config.LocalDriverConfig
+		if err := DecodeConfig(cfg, &c); err != nil {
+			return nil, err
+		}
+		fs := local.NewStorage(c.Root, c.BaseUrl)
+		fs.RestrictSymlinks = c.RestrictSymlinks
+		fs.FileMode, _ = config.ParseFileMode(c.FileMode)
+		fs.DirMode, _ = config.ParseFileMode(c.DirMode)
+		fs.SyncDir = c.SyncDir
+		fs.SignKey = c.SignKey
+		return fs, nil
+	})
+	RegisterDriver("qiniu", func(cfg any) (Filesystem, error) {
+		var c config.QiniuDriverConfig
+		if err := DecodeConfig(cfg, &c); err != nil {
+			return nil, err
+		}
+		bucket := qiniu.Bucket{
+			Name:            c.Bucket,
+			Domain:          c.Domain,
+			TimestampEncKey: c.TimestampEncKey,
+			Private:         c.Private,
+		}
+		return qiniu.NewStorage(c.AccessKey, c.AccessSecret, bucket), nil
+	})
+	RegisterDriver("webdav", func(cfg any) (Filesystem, error) {
+		var c config.WebdavDriverConfig
+		if err := DecodeConfig(cfg, &c); err != nil {
+			return nil, err
+		}
+		fs, err := webdav.NewStorage(c.Uri, c.Username, c.Password)
+		if err != nil {
+			return nil, err
+		}
+		fs.ProxyUrl = c.ProxyUrl
+		fs.SignKey = c.SignKey
+		return fs, nil
+	})
+	RegisterDriver("memory", func(cfg any) (Filesystem, error) {
+		var c config.MemoryDriverConfig
+		if err := DecodeConfig(cfg, &c); err != nil {
+			return nil, err
+		}
+		return memory.NewStorage(c.BaseUrl), nil
+	})
+}
+
+// RegisterDriver 注册文件系统驱动，注册后可以通过 NewStorageWithError 按名称创建
+// 通常在驱动包的 init 中调用，名称为空、factory 为 nil 或重复注册时 panic
+func RegisterDriver(name string, factory DriverFactory) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+
+	if name == "" {
+		panic("filesystem: RegisterDriver name is empty")
+	}
+	if factory == nil {
+		panic("filesystem: RegisterDriver factory is nil for driver " + name)
+	}
+	if _, dup := drivers[name]; dup {
+		panic("filesystem: RegisterDriver called twice for driver " + name)
+	}
+	drivers[name] = factory
+}
+
+// Drivers 返回已注册的驱动名称，按字母排序
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupDriver(name string) (DriverFactory, error) {
+	if name == "" {
+		return nil, fmt.Errorf("%w: driver name is empty", ErrUnknownDriver)
+	}
+
+	driversMu.RLock()
+	factory, ok := drivers[name]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf(`%w: %q (optional drivers register when their package is imported, e.g. import _ "github.com/yu1ec/go-filesystem/driver/%s" or import _ "github.com/yu1ec/go-filesystem/driver/all")`, ErrUnknownDriver, name, name)
+	}
+	return factory, nil
+}
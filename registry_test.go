@@ -0,0 +1,100 @@
+package filesystem_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/yu1ec/go-filesystem"
+	"github.com/yu1ec/go-filesystem/config"
+	"github.com/yu1ec/go-filesystem/driver/memory"
+
+	_ "github.com/yu1ec/go-filesystem/driver/all"
+)
+
+func TestRegisterDriver(t *testing.T) {
+	type customConfig struct {
+		BaseUrl string `yaml:"base_url"`
+	}
+
+	filesystem.RegisterDriver("custom_test", func(cfg any) (filesystem.Filesystem, error) {
+		var c customConfig
+		if err := filesystem.DecodeConfig(cfg, &c); err != nil {
+			return nil, err
+		}
+		return memory.NewStorage(c.BaseUrl), nil
+	})
+
+	t.Run("自定义驱动", func(t *testing.T) {
+		fs, err := filesystem.NewStorageWithError(config.FilesystemDriver{
+			Name:   "custom_test",
+			Config: map[string]any{"base_url": "http://cdn.example.com"},
+		})
+		if err != nil {
+			t.Fatalf("NewStorageWithError失败：%v", err)
+		}
+		if url := fs.GetUrl("a.txt"); url != "http://cdn.example.com/a.txt" {
+			t.Errorf("配置未正确解码，URL：%s", url)
+		}
+	})
+
+	t.Run("内置驱动", func(t *testing.T) {
+		drivers := filesystem.Drivers()
+		// local、memory、qiniu、webdav 为内置驱动，其他驱动由测试导入的 driver/all 注册
+		for _, name := range []string{"local", "memory", "qiniu", "webdav", "s3", "oss", "cos", "sftp", "ftp", "azblob", "gcs", "archive", "custom_test"} {
+			if !slices.Contains(drivers, name) {
+				t.Errorf("驱动 %s 未注册，已注册：%v", name, drivers)
+			}
+		}
+
+		fs, err := filesystem.NewStorageWithError(config.FilesystemDriver{Name: "memory"})
+		if err != nil {
+			t.Fatalf("NewStorageWithError失败：%v", err)
+		}
+		if err := fs.Put(context.Background(), "a.txt", []byte("data")); err != nil {
+			t.Errorf("Put失败：%v", err)
+		}
+	})
+
+	t.Run("未知驱动", func(t *testing.T) {
+		for _, name := range []string{"", "unknown"} {
+			fs, err := filesystem.NewStorageWithError(config.FilesystemDriver{Name: name})
+			if !errors.Is(err, filesystem.ErrUnknownDriver) {
+				t.Errorf("驱动 %q 期望 ErrUnknownDriver，实际：%v", name, err)
+			}
+			if fs != nil {
+				t.Errorf("驱动 %q 不应返回文件系统", name)
+			}
+		}
+
+		// 错误信息提示需要导入驱动包
+		_, err := filesystem.NewStorageWithError(config.FilesystemDriver{Name: "unknown"})
+		if err == nil || !strings.Contains(err.Error(), "github.com/yu1ec/go-filesystem/driver/all") {
+			t.Errorf("错误信息应该提示导入驱动包：%v", err)
+		}
+	})
+
+	t.Run("NewStorage出错时panic", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("未知驱动应该 panic")
+			} else if err, ok := r.(error); !ok || !errors.Is(err, filesystem.ErrUnknownDriver) {
+				t.Errorf("期望 ErrUnknownDriver，实际：%v", r)
+			}
+		}()
+		filesystem.NewStorage(config.FilesystemDriver{Name: "unknown"})
+	})
+
+	t.Run("重复注册", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("重复注册应该 panic")
+			}
+		}()
+		filesystem.RegisterDriver("memory", func(cfg any) (filesystem.Filesystem, error) {
+			return nil, nil
+		})
+	})
+}
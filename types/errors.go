@@ -0,0 +1,45 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 各驱动共用的错误，驱动会将自身的错误转换为以下错误
+// 可以使用 errors.Is(err, types.ErrNotFound) 判断，不需要关心具体的驱动
+var (
+	ErrNotFound      = errors.New("file not found")       // 文件不存在
+	ErrPermission    = errors.New("permission denied")    // 没有权限
+	ErrAlreadyExists = errors.New("file already exists")  // 目标文件已存在
+	ErrInvalidPath   = errors.New("invalid path")         // 路径不合法
+	ErrReadOnly      = errors.New("read-only filesystem") // 文件系统只读
+)
+
+// PathError 记录出错的操作和文件路径
+type PathError struct {
+	Op   string // 操作名称
+	Path string // 文件路径
+	Err  error  // 具体错误
+}
+
+func (e *PathError) Error() string {
+	return e.Op + " " + e.Path + ": " + e.Err.Error()
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// NewPathError 创建路径错误
+// kind: 预定义的错误，如 ErrNotFound，无法归类时传 nil
+// cause: 驱动返回的原始错误，同样可以通过 errors.Is 和 errors.As 获取
+func NewPathError(op, path string, kind, cause error) *PathError {
+	err := kind
+	switch {
+	case kind == nil:
+		err = cause
+	case cause != nil:
+		err = fmt.Errorf("%w: %w", kind, cause)
+	}
+	return &PathError{Op: op, Path: path, Err: err}
+}
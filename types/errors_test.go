@@ -0,0 +1,50 @@
+package types_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/yu1ec/go-filesystem/types"
+)
+
+func TestNewPathError(t *testing.T) {
+	t.Run("归类错误", func(t *testing.T) {
+		err := types.NewPathError("get", "a.txt", types.ErrNotFound, os.ErrNotExist)
+
+		if !errors.Is(err, types.ErrNotFound) {
+			t.Error("期望匹配ErrNotFound")
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Error("期望保留原始错误")
+		}
+		if errors.Is(err, types.ErrPermission) {
+			t.Error("不应匹配ErrPermission")
+		}
+
+		var pathErr *types.PathError
+		if !errors.As(err, &pathErr) || pathErr.Op != "get" || pathErr.Path != "a.txt" {
+			t.Errorf("PathError不正确：%#v", pathErr)
+		}
+
+		expected := "get a.txt: file not found: file does not exist"
+		if err.Error() != expected {
+			t.Errorf("错误信息不正确。期望：%s，实际：%s", expected, err.Error())
+		}
+	})
+
+	t.Run("无原始错误", func(t *testing.T) {
+		err := types.NewPathError("copy", "b.txt", types.ErrAlreadyExists, nil)
+		if !errors.Is(err, types.ErrAlreadyExists) || err.Error() != "copy b.txt: file already exists" {
+			t.Errorf("错误不正确：%v", err)
+		}
+	})
+
+	t.Run("无法归类", func(t *testing.T) {
+		cause := errors.New("network error")
+		err := types.NewPathError("stat", "c.txt", nil, cause)
+		if !errors.Is(err, cause) || errors.Is(err, types.ErrNotFound) {
+			t.Errorf("错误不正确：%v", err)
+		}
+	})
+}
@@ -0,0 +1,26 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileInfo 文件信息
+type FileInfo struct {
+	Path         string    // 文件路径
+	Size         int64     // 文件大小 单位/字节
+	LastModified time.Time // 最后修改时间
+	ContentType  string    // MIME类型
+	ETag         string    // 文件哈希或ETag, 不同驱动的计算方式不同, 仅用于判断内容是否变化
+	IsDir        bool      // 是否为目录 仅在非递归列举时出现
+}
+
+// QuotedETag 返回可以写入 HTTP ETag 响应头的值，ETag 为空时返回空字符串
+// 七牛、GCS 等驱动返回的 ETag 不带引号，需要加上引号才能用于条件请求
+func (f FileInfo) QuotedETag() string {
+	if f.ETag == "" || strings.HasPrefix(f.ETag, `"`) || strings.HasPrefix(f.ETag, `W/"`) {
+		return f.ETag
+	}
+	return strconv.Quote(f.ETag)
+}
@@ -0,0 +1,41 @@
+package types
+
+import "sort"
+
+// ListOptions 列举选项
+type ListOptions struct {
+	Recursive bool   // 是否递归列举子目录下的文件, 递归时不返回目录
+	Limit     int    // 每页最大数量, 小于等于0时由驱动决定
+	Cursor    string // 分页游标, 传入上一页返回的 NextCursor
+}
+
+// ListResult 列举结果
+type ListResult struct {
+	Files      []FileInfo // 文件及目录列表
+	NextCursor string     // 下一页游标, 为空表示没有更多数据
+}
+
+// Paginate 对一次性获取的完整列表进行排序和分页
+// 游标为上一页最后一个文件的路径
+func Paginate(files []FileInfo, cursor string, limit int) ListResult {
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	if cursor != "" {
+		start := sort.Search(len(files), func(i int) bool {
+			return files[i].Path > cursor
+		})
+		files = files[start:]
+	}
+
+	if limit <= 0 || len(files) <= limit {
+		return ListResult{Files: files}
+	}
+
+	files = files[:limit]
+	return ListResult{
+		Files:      files,
+		NextCursor: files[limit-1].Path,
+	}
+}
@@ -0,0 +1,38 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+)
+
+// SkipAll 在 WalkFunc 中返回时停止遍历，Walk 返回 nil
+var SkipAll = fs.SkipAll
+
+// WalkFunc 遍历文件的回调函数
+type WalkFunc func(info FileInfo) error
+
+// Walk 递归遍历 prefix 目录下的所有文件，自动处理分页
+func Walk(ctx context.Context, fsys Filesystem, prefix string, fn WalkFunc) error {
+	opts := ListOptions{Recursive: true}
+	for {
+		result, err := fsys.List(ctx, prefix, opts)
+		if err != nil {
+			return err
+		}
+
+		for _, info := range result.Files {
+			if err := fn(info); err != nil {
+				if errors.Is(err, SkipAll) {
+					return nil
+				}
+				return err
+			}
+		}
+
+		if result.NextCursor == "" {
+			return nil
+		}
+		opts.Cursor = result.NextCursor
+	}
+}
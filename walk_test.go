@@ -0,0 +1,61 @@
+package filesystem_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/yu1ec/go-filesystem"
+	"github.com/yu1ec/go-filesystem/driver/local"
+)
+
+func TestWalk(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "walk_test")
+	if err != nil {
+		t.Fatalf("无法创建临时目录：%v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	fs := local.NewStorage(tempDir, "")
+	for _, p := range []string{"a.txt", "dir/b.txt", "dir/sub/c.txt"} {
+		if err := fs.Put(context.Background(), p, []byte(p)); err != nil {
+			t.Fatalf("Put失败：%v", err)
+		}
+	}
+
+	t.Run("遍历全部文件", func(t *testing.T) {
+		var paths []string
+		err := filesystem.Walk(context.Background(), fs, "", func(info filesystem.FileInfo) error {
+			paths = append(paths, info.Path)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Walk失败：%v", err)
+		}
+		if len(paths) != 3 {
+			t.Errorf("文件数量不正确。期望：3，实际：%d %v", len(paths), paths)
+		}
+	})
+
+	t.Run("SkipAll提前结束", func(t *testing.T) {
+		count := 0
+		err := filesystem.Walk(context.Background(), fs, "", func(info filesystem.FileInfo) error {
+			count++
+			return filesystem.SkipAll
+		})
+		if err != nil || count != 1 {
+			t.Errorf("SkipAll未生效。err：%v，count：%d", err, count)
+		}
+	})
+
+	t.Run("回调错误", func(t *testing.T) {
+		wantErr := errors.New("stop")
+		err := filesystem.Walk(context.Background(), fs, "dir", func(info filesystem.FileInfo) error {
+			return wantErr
+		})
+		if !errors.Is(err, wantErr) {
+			t.Errorf("期望返回回调错误，实际：%v", err)
+		}
+	})
+}